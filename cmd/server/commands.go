@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/markopolo123/prompt-mcp/internal/config"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// command is a subcommand of the server binary. run receives the arguments
+// after the subcommand name and returns the process exit code.
+type command struct {
+	summary string
+	run     func(args []string) int
+}
+
+// commands holds the subcommands by name. Without a subcommand the binary
+// runs the MCP server.
+var commands = map[string]command{
+	"lint":     {summary: "Check prompts for quality problems", run: runLint},
+	"new":      {summary: "Create a new prompt file", run: runNew},
+	"render":   {summary: "Render a prompt with arguments", run: runRender},
+	"search":   {summary: "Search the prompt library", run: runSearch},
+	"validate": {summary: "Validate prompt files, for use in CI", run: runValidate},
+}
+
+// printCommands lists the subcommands for usage output
+func printCommands() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(flag.CommandLine.Output(), "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(flag.CommandLine.Output(), "\nRun '%s <command> -h' for the options of a command.\n", filepath.Base(os.Args[0]))
+}
+
+// parseInterspersed parses flags that may come before or after positional
+// arguments, as in "render <id> -arg name=value", and returns the positional
+// arguments
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// commandFlags are the flags shared by all subcommands
+type commandFlags struct {
+	configPath *string
+	promptsDir *string
+}
+
+// addCommandFlags registers the shared flags on a subcommand's flag set
+func addCommandFlags(flags *flag.FlagSet) *commandFlags {
+	return &commandFlags{
+		configPath: flags.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "Path to YAML configuration file"),
+		promptsDir: flags.String("prompts-dir", "", "Directory containing prompt files (default from configuration)"),
+	}
+}
+
+// config resolves the configuration the same way the server does, with an
+// explicit -prompts-dir taking precedence
+func (f *commandFlags) config() (config.Config, error) {
+	cfg, err := config.Load(*f.configPath)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if *f.promptsDir != "" {
+		cfg.Storage.PromptsDir = *f.promptsDir
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// loader returns a loader for the configured prompts directory and load mode
+func (f *commandFlags) loader() (*prompt.Loader, error) {
+	cfg, err := f.config()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(cfg.Storage.PromptsDir); err != nil {
+		return nil, fmt.Errorf("prompts directory does not exist: %s", cfg.Storage.PromptsDir)
+	}
+	loader := prompt.NewLoader(cfg.Storage.PromptsDir)
+	loader.SetMode(prompt.LoadMode(cfg.Storage.LoadMode))
+	return loader, nil
+}
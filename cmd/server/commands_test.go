@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStdout runs a command and returns its exit status and what it
+// wrote to standard output
+func captureStdout(t *testing.T, run func() int) (int, string) {
+	t.Helper()
+	return captureFile(t, &os.Stdout, run)
+}
+
+// captureStderr runs a command and returns its exit status and what it
+// wrote to standard error
+func captureStderr(t *testing.T, run func() int) (int, string) {
+	t.Helper()
+	return captureFile(t, &os.Stderr, run)
+}
+
+// captureFile replaces *target with a pipe while run executes and returns
+// the exit status and everything written to the pipe
+func captureFile(t *testing.T, target **os.File, run func() int) (int, string) {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	original := *target
+	*target = writer
+	defer func() { *target = original }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		output <- string(data)
+	}()
+
+	status := run()
+	writer.Close()
+	return status, <-output
+}
+
+func TestParseInterspersed(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	format := flags.String("format", "text", "")
+	verbose := flags.Bool("v", false, "")
+
+	positional := parseInterspersed(flags, []string{"a.yaml", "-format", "json", "b.yaml", "-v"})
+	if strings.Join(positional, ",") != "a.yaml,b.yaml" || *format != "json" || !*verbose {
+		t.Errorf("Expected two files with both flags set, got %v, format %q, verbose %v", positional, *format, *verbose)
+	}
+}
+
+func TestValidateFlagsAfterDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "dev/good.yaml", strings.Replace(validPrompt, "%s", "good", 1))
+
+	status, out := captureStdout(t, func() int { return runValidate([]string{dir, "-format", "json"}) })
+	var report validationReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if status != 0 || report.Checked != 1 || report.Errors != 0 {
+		t.Errorf("Expected one valid file, got status %d and %+v", status, report)
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/markopolo123/prompt-mcp/internal/lint"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// ruleValidation is the rule of problems that prevent a prompt from loading.
+// It cannot be configured or disabled.
+const ruleValidation = "validation"
+
+// lintReport is the JSON output of the lint command
+type lintReport struct {
+	Checked  int            `json:"checked"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Problems []lint.Problem `json:"problems"`
+}
+
+// runLint implements the lint subcommand
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	shared := addCommandFlags(flags)
+	format := flags.String("format", formatText, "Output format: text, json, github or gitlab")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s lint [options] [directory | files...]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Checks prompts for quality problems beyond validation. Rule severities are set")
+		fmt.Fprintln(flags.Output(), "in the lint section of the configuration file. Files are selected as for")
+		fmt.Fprintln(flags.Output(), "validate. Exits with status 1 if there are errors; warnings do not fail.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+	files := parseInterspersed(flags, args)
+
+	switch *format {
+	case formatText, formatJSON, formatGitHub, formatGitLab:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid format %q, must be text, json, github or gitlab\n", *format)
+		return 2
+	}
+
+	cfg, err := shared.config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// A single directory argument replaces the configured prompts directory
+	promptsDir := cfg.Storage.PromptsDir
+	if len(files) == 1 {
+		if info, err := os.Stat(files[0]); err == nil && info.IsDir() {
+			promptsDir, files = files[0], nil
+		}
+	}
+
+	report := lintPrompts(promptsDir, files, cfg.LintConfig())
+	if err := writeLintReport(os.Stdout, *format, report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// lintPrompts loads the whole prompts directory leniently and lints the
+// prompts in files, or every prompt if files is empty. Files that fail to
+// load are reported as validation errors.
+func lintPrompts(promptsDir string, files []string, config lint.Config) lintReport {
+	report := lintReport{Problems: []lint.Problem{}}
+
+	if _, err := os.Stat(promptsDir); err != nil {
+		return report.add(validationProblem(prompt.Diagnostic{FilePath: promptsDir, Message: "prompts directory does not exist"}))
+	}
+
+	loader := prompt.NewLoader(promptsDir)
+	loader.SetMode(prompt.LoadLenient)
+	library, err := loader.LoadAllPrompts()
+	if err != nil {
+		return report.add(validationProblem(prompt.Diagnostic{FilePath: filepath.Join(promptsDir, prompt.PartialsDir), Message: err.Error()}))
+	}
+
+	// checked holds the distinct files checked, as a file can have several
+	// load problems
+	checked := make(map[string]bool)
+	include := func(string) bool { return true }
+	if len(files) > 0 {
+		selected, checkAll, outside := selectFiles(promptsDir, files)
+		for _, file := range outside {
+			report = report.add(validationProblem(prompt.Diagnostic{FilePath: file, Message: fmt.Sprintf("file is not in the prompts directory %s", promptsDir)}))
+			checked[file] = true
+		}
+		include = func(path string) bool { return checkAll || selected[absPath(path)] }
+	}
+
+	for _, diagnostic := range library.Diagnostics {
+		if include(diagnostic.FilePath) {
+			report = report.add(validationProblem(diagnostic))
+			checked[diagnostic.FilePath] = true
+		}
+	}
+
+	linter := lint.New(config)
+	for _, p := range library.ListPrompts() {
+		if !include(p.FilePath) {
+			continue
+		}
+		checked[p.FilePath] = true
+
+		source, err := os.ReadFile(p.FilePath)
+		if err != nil {
+			report = report.add(validationProblem(prompt.Diagnostic{FilePath: p.FilePath, PromptID: p.Metadata.ID, Message: err.Error()}))
+			continue
+		}
+		for _, problem := range linter.Lint(p, source) {
+			report = report.add(problem)
+		}
+	}
+
+	report.Checked = len(checked)
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+// validationProblem reports a prompt that failed to load as a lint error
+func validationProblem(d prompt.Diagnostic) lint.Problem {
+	return lint.Problem{Diagnostic: d, Rule: ruleValidation, Severity: lint.SeverityError}
+}
+
+// add appends a problem to the report
+func (r lintReport) add(problem lint.Problem) lintReport {
+	r.Problems = append(r.Problems, problem)
+	if problem.Severity == lint.SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+	return r
+}
+
+// writeLintReport writes the report in the given format, with file paths
+// relative to the working directory where possible
+func writeLintReport(w io.Writer, format string, report lintReport) error {
+	for i := range report.Problems {
+		report.Problems[i].FilePath = displayPath(report.Problems[i].FilePath)
+	}
+
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+
+	case formatGitHub:
+		for _, p := range report.Problems {
+			writeGitHubCommand(w, string(p.Severity), fmt.Sprintf("Prompt lint (%s)", p.Rule), p.Diagnostic)
+		}
+		return nil
+
+	case formatGitLab:
+		issues := make([]gitLabIssue, len(report.Problems))
+		for i, p := range report.Problems {
+			issues[i] = newGitLabIssue("prompt-lint/"+p.Rule, p.Diagnostic)
+			if p.Severity == lint.SeverityWarning {
+				issues[i].Severity = "minor"
+			}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(issues)
+
+	default:
+		for _, p := range report.Problems {
+			fmt.Fprintln(w, p)
+		}
+		fmt.Fprintf(w, "%d prompt files checked, %d errors, %d warnings\n", report.Checked, report.Errors, report.Warnings)
+		return nil
+	}
+}
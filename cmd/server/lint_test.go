@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/lint"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+func TestLintPrompts(t *testing.T) {
+	dir := t.TempDir()
+	good := writePromptFile(t, dir, "dev/good.yaml", strings.Replace(validPrompt, "%s", "good", 1))
+	writePromptFile(t, dir, "dev/broken.yaml", "metadata: [unclosed\n")
+
+	// The test prompt has no tags and a short description
+	report := lintPrompts(dir, nil, lint.DefaultConfig())
+	if report.Checked != 2 || report.Errors != 1 || report.Warnings != 2 {
+		t.Errorf("Expected 1 error and 2 warnings in 2 files, got %+v", report)
+	}
+	if report.Problems[0].Rule != ruleValidation || !strings.HasSuffix(report.Problems[0].FilePath, "broken.yaml") {
+		t.Errorf("Expected the broken file to be reported first, got %v", report.Problems[0])
+	}
+
+	config := lint.DefaultConfig()
+	config.Rules = map[string]lint.Severity{lint.RuleMissingTags: lint.SeverityError, lint.RuleShortDescription: lint.SeverityOff}
+	report = lintPrompts(dir, []string{good, filepath.Join(dir, "README.md")}, config)
+	if report.Checked != 1 || report.Errors != 1 || report.Warnings != 0 || report.Problems[0].Rule != lint.RuleMissingTags {
+		t.Errorf("Expected only a missing tags error, got %+v", report)
+	}
+
+	var out bytes.Buffer
+	if err := writeLintReport(&out, formatGitHub, report); err != nil {
+		t.Fatalf("Failed to write github report: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "::error file=") || !strings.Contains(out.String(), "title=Prompt lint (missing-tags)::prompt has no tags") {
+		t.Errorf("Unexpected github output %q", out.String())
+	}
+}
+
+func TestLintCountsFiles(t *testing.T) {
+	dir := t.TempDir()
+	invalid := strings.Replace(validPrompt, "%s", "invalid", 1)
+	invalid = strings.Replace(invalid, `name: "Prompt"`, `name: ""`, 1)
+	invalid = strings.Replace(invalid, `version: "1.0.0"`, `version: "one"`, 1)
+	writePromptFile(t, dir, "dev/invalid.yaml", invalid)
+
+	report := lintPrompts(dir, nil, lint.DefaultConfig())
+	if report.Checked != 1 || report.Errors < 2 {
+		t.Errorf("Expected several errors in 1 file, got %+v", report)
+	}
+}
+
+func TestLintGitLabFingerprints(t *testing.T) {
+	whitespace := func(line int) lint.Problem {
+		return lint.Problem{
+			Diagnostic: prompt.Diagnostic{FilePath: "dev/review.yaml", Line: line, Message: "trailing whitespace"},
+			Rule:       lint.RuleTrailingWhitespace,
+			Severity:   lint.SeverityWarning,
+		}
+	}
+	tags := lint.Problem{Diagnostic: prompt.Diagnostic{FilePath: "dev/review.yaml", Line: 3, Message: "trailing whitespace"}, Rule: lint.RuleMissingTags, Severity: lint.SeverityWarning}
+	report := lintReport{Problems: []lint.Problem{whitespace(3), whitespace(7), tags}}
+
+	var out bytes.Buffer
+	if err := writeLintReport(&out, formatGitLab, report); err != nil {
+		t.Fatalf("Failed to write gitlab report: %v", err)
+	}
+	var issues []gitLabIssue
+	if err := json.Unmarshal(out.Bytes(), &issues); err != nil {
+		t.Fatalf("Failed to decode gitlab report: %v", err)
+	}
+
+	fingerprints := make(map[string]bool)
+	for _, issue := range issues {
+		fingerprints[issue.Fingerprint] = true
+	}
+	if len(issues) != 3 || len(fingerprints) != 3 || issues[0].CheckName != "prompt-lint/trailing-whitespace" || issues[0].Severity != "minor" {
+		t.Errorf("Expected 3 distinct minor issues, got %+v", issues)
+	}
+}
+
+func TestLintFlagsAfterDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "dev/good.yaml", strings.Replace(validPrompt, "%s", "good", 1))
+
+	status, out := captureStdout(t, func() int { return runLint([]string{dir, "-format", "json"}) })
+	var report lintReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out, err)
+	}
+	if status != 0 || report.Checked != 1 || report.Errors != 0 {
+		t.Errorf("Expected one file without errors, got status %d and %+v", status, report)
+	}
+}
@@ -3,32 +3,90 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 
+	"github.com/markopolo123/prompt-mcp/internal/config"
 	"github.com/markopolo123/prompt-mcp/internal/server"
 )
 
 func main() {
+	// Subcommands; without one the MCP server is started
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			os.Exit(command.run(os.Args[2:]))
+		}
+	}
+
+	defaults := config.Default()
+
 	// Command line flags
 	var (
-		promptsDir = flag.String("prompts-dir", "./prompts", "Directory containing prompt files")
+		configPath = flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "Path to YAML configuration file")
+		promptsDir = flag.String("prompts-dir", defaults.Storage.PromptsDir, "Directory containing prompt files")
 		version    = flag.Bool("version", false, "Print version and exit")
-		name       = flag.String("name", "team-prompt-server", "Server name")
-		ver        = flag.String("ver", "1.0.0", "Server version")
+		name       = flag.String("name", defaults.Server.Name, "Server name")
+		ver        = flag.String("ver", defaults.Server.Version, "Server version")
+		transport  = flag.String("transport", defaults.Server.Transport, "Transport to serve: stdio, sse or streamable-http")
+		listen     = flag.String("listen", defaults.Server.ListenAddr, "Listen address for HTTP transports")
+		watch      = flag.Bool("watch", defaults.Storage.WatchChanges, "Reload prompts automatically when files change")
+		debounce   = flag.Duration("watch-debounce", defaults.Storage.WatchDebounce, "Quiet period before reloading after a change")
+		embedRoot  = flag.String("embed-root", defaults.Embed.Root, "Directory that resource messages may embed files from")
+		loadMode   = flag.String("load-mode", defaults.Storage.LoadMode, "How invalid prompt files are handled: strict fails startup, lenient skips them")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options]\n       %s <command> [options]\n\nOptions:\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+		printCommands()
+	}
 	flag.Parse()
 
+	// -version must work even when the configuration is broken
 	if *version {
 		log.Printf("%s v%s", *name, *ver)
 		os.Exit(0)
 	}
 
+	// Resolve configuration: defaults, then file, then environment
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Explicitly set flags take precedence over everything else
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "prompts-dir":
+			cfg.Storage.PromptsDir = *promptsDir
+		case "name":
+			cfg.Server.Name = *name
+		case "ver":
+			cfg.Server.Version = *ver
+		case "transport":
+			cfg.Server.Transport = *transport
+		case "listen":
+			cfg.Server.ListenAddr = *listen
+		case "watch":
+			cfg.Storage.WatchChanges = *watch
+		case "watch-debounce":
+			cfg.Storage.WatchDebounce = *debounce
+		case "embed-root":
+			cfg.Embed.Root = *embedRoot
+		case "load-mode":
+			cfg.Storage.LoadMode = *loadMode
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Ensure prompts directory exists and is absolute
-	absPromptsDir, err := filepath.Abs(*promptsDir)
+	absPromptsDir, err := filepath.Abs(cfg.Storage.PromptsDir)
 	if err != nil {
 		log.Fatalf("Failed to resolve prompts directory path: %v", err)
 	}
@@ -36,17 +94,10 @@ func main() {
 	if _, err := os.Stat(absPromptsDir); os.IsNotExist(err) {
 		log.Fatalf("Prompts directory does not exist: %s", absPromptsDir)
 	}
-
-	// Create server configuration
-	config := server.Config{
-		Name:         *name,
-		Version:      *ver,
-		PromptsDir:   absPromptsDir,
-		WatchChanges: false, // TODO: Implement file watching
-	}
+	cfg.Storage.PromptsDir = absPromptsDir
 
 	// Create and initialize server
-	srv, err := server.NewServer(config)
+	srv, err := server.NewServer(cfg.ServerConfig())
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// scaffoldVersion is the version of a new prompt
+const scaffoldVersion = "0.1.0"
+
+// scaffold holds the values of a new prompt file
+type scaffold struct {
+	ID          string
+	Name        string
+	Description string
+	Author      string
+	Category    string
+	Tags        []string
+	Messages    bool // scaffold system and user messages instead of a single prompt
+	Created     time.Time
+}
+
+// scaffoldTemplate is the layout of a new prompt file. The body and the
+// example argument are placeholders to be edited.
+var scaffoldTemplate = template.Must(template.New("prompt").Funcs(template.FuncMap{
+	"quote": quoteYAML,
+	"time":  func(t time.Time) string { return quoteYAML(t.UTC().Format(time.RFC3339)) },
+}).Parse(`metadata:
+  id: {{quote .ID}}
+  name: {{quote .Name}}
+  description: {{quote .Description}}
+  author: {{quote .Author}}
+  created: {{time .Created}}
+  modified: {{time .Created}}
+  version: {{quote .Version}}
+{{- if .Tags}}
+  tags:
+{{- range .Tags}}
+    - {{quote .}}
+{{- end}}
+{{- end}}
+
+arguments:
+  - name: "input"
+    description: "The input to work on"
+    type: "string"
+    required: true
+{{if .Messages}}
+messages:
+  - role: "system"
+    content: |
+      Describe the role and behaviour of the assistant here.
+  - role: "user"
+    content: |
+      Describe the task here.
+
+      {{"{{"}}input{{"}}"}}
+{{- else}}
+prompt: |
+  Describe the task here.
+
+  {{"{{"}}input{{"}}"}}
+{{- end}}
+
+usage_stats:
+  usage_count: 0
+  last_used: {{time .Created}}
+`))
+
+// runNew implements the new subcommand
+func runNew(args []string) int {
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	shared := addCommandFlags(flags)
+	category := flags.String("category", "", "Category directory of the prompt")
+	name := flags.String("name", "", "Display name (default derived from the ID)")
+	description := flags.String("description", "", "Short description of the prompt")
+	tags := flags.String("tags", "", "Comma-separated tags")
+	author := flags.String("author", "", "Author (default from git config user.name)")
+	messages := flags.Bool("messages", false, "Scaffold system and user messages instead of a single prompt")
+	noInput := flags.Bool("no-input", false, "Never ask for missing values, even on a terminal")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s new [options] [id]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Creates a valid prompt file in the category directory, ready to be edited.")
+		fmt.Fprintln(flags.Output(), "On a terminal, values not given as options are asked for.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := shared.config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	s := scaffold{
+		Name:        *name,
+		Description: *description,
+		Author:      *author,
+		Category:    *category,
+		Tags:        splitTags(*tags),
+		Messages:    *messages,
+		Created:     time.Now().UTC().Truncate(time.Second),
+	}
+	if len(positional) == 1 {
+		s.ID = positional[0]
+	}
+	if s.Author == "" {
+		s.Author = gitUserName()
+	}
+
+	if !*noInput && isTerminal(os.Stdin) {
+		s = askMissing(bufio.NewReader(os.Stdin), os.Stderr, s, listCategories(cfg.Storage.PromptsDir))
+	}
+	if s.Name == "" {
+		s.Name = titleFromID(s.ID)
+	}
+
+	path, err := createPrompt(cfg.Storage.PromptsDir, s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("Created %s\n", displayPath(path))
+	return 0
+}
+
+// createPrompt checks the scaffold and writes it to its category directory,
+// returning the path of the new file
+func createPrompt(promptsDir string, s scaffold) (string, error) {
+	switch {
+	case s.ID == "":
+		return "", errors.New("a prompt ID is required")
+	case s.Description == "":
+		return "", errors.New("a description is required, use -description")
+	case s.Author == "":
+		return "", errors.New("an author is required, set git config user.name or use -author")
+	case s.Category != "" && !prompt.IsValidCategory(s.Category):
+		return "", fmt.Errorf("invalid category '%s'", s.Category)
+	}
+
+	data, err := s.render()
+	if err != nil {
+		return "", err
+	}
+
+	// The scaffold must load, so check it as the loader would
+	var p prompt.Prompt
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return "", fmt.Errorf("failed to parse generated prompt: %w", err)
+	}
+	if errs := prompt.ValidatePromptAll(&p); len(errs) > 0 {
+		return "", fmt.Errorf("invalid prompt: %w", errors.Join(errs...))
+	}
+
+	if _, err := os.Stat(promptsDir); err == nil {
+		// Invalid files elsewhere in the library must not prevent new prompts
+		loader := prompt.NewLoader(promptsDir)
+		loader.SetMode(prompt.LoadLenient)
+		library, err := loader.LoadAllPrompts()
+		if err != nil {
+			return "", err
+		}
+		if existing, exists := library.GetPrompt(s.ID); exists {
+			return "", fmt.Errorf("prompt '%s' already exists in %s", s.ID, displayPath(existing.FilePath))
+		}
+		// A skipped file keeps its ID and would clash once it is fixed
+		for _, diagnostic := range library.Diagnostics {
+			if declaredID(diagnostic.FilePath) == s.ID {
+				return "", fmt.Errorf("prompt '%s' already exists in %s, which currently fails to load", s.ID, displayPath(diagnostic.FilePath))
+			}
+		}
+	}
+
+	path := filepath.Join(promptsDir, s.Category, s.ID+".yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("file %s already exists", displayPath(path))
+		}
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return path, nil
+}
+
+// declaredID returns the metadata ID a prompt file declares, whether or not
+// the prompt is valid. It returns "" if the file cannot be read or parsed.
+func declaredID(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var file struct {
+		Metadata struct {
+			ID string `yaml:"id"`
+		} `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return ""
+	}
+	return file.Metadata.ID
+}
+
+// render generates the YAML of the scaffold
+func (s scaffold) render() ([]byte, error) {
+	var out strings.Builder
+	data := struct {
+		scaffold
+		Version string
+	}{s, scaffoldVersion}
+	if err := scaffoldTemplate.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to generate prompt: %w", err)
+	}
+	return []byte(out.String()), nil
+}
+
+// askMissing asks for the values of the scaffold that were not given,
+// offering defaults where there are any
+func askMissing(in *bufio.Reader, out io.Writer, s scaffold, categories []string) scaffold {
+	if s.ID == "" {
+		s.ID = ask(in, out, "ID", "", true)
+	}
+	if s.Name == "" {
+		s.Name = ask(in, out, "Name", titleFromID(s.ID), false)
+	}
+	if s.Description == "" {
+		s.Description = ask(in, out, "Description", "", true)
+	}
+	if s.Author == "" {
+		s.Author = ask(in, out, "Author", "", true)
+	}
+	if s.Category == "" {
+		label := "Category"
+		if len(categories) > 0 {
+			label = fmt.Sprintf("Category (%s)", strings.Join(categories, ", "))
+		}
+		s.Category = ask(in, out, label, "", false)
+	}
+	if len(s.Tags) == 0 {
+		s.Tags = splitTags(ask(in, out, "Tags, comma-separated", "", false))
+	}
+	return s
+}
+
+// ask reads a value from in, returning def for an empty answer. A required
+// value is asked for again until it is given or the input ends.
+func ask(in *bufio.Reader, out io.Writer, label, def string, required bool) string {
+	for {
+		if def != "" {
+			fmt.Fprintf(out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(out, "%s: ", label)
+		}
+
+		line, err := in.ReadString('\n')
+		if answer := strings.TrimSpace(line); answer != "" {
+			return answer
+		}
+		if def != "" || !required || err != nil {
+			return def
+		}
+	}
+}
+
+// listCategories returns the category directories of the prompts directory
+func listCategories(promptsDir string) []string {
+	entries, err := os.ReadDir(promptsDir)
+	if err != nil {
+		return nil
+	}
+
+	var categories []string
+	for _, entry := range entries {
+		if entry.IsDir() && prompt.IsValidCategory(entry.Name()) {
+			categories = append(categories, entry.Name())
+		}
+	}
+	return categories
+}
+
+// gitUserName returns the user name from git config, or an empty string
+func gitUserName() string {
+	out, err := exec.Command("git", "config", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// titleFromID derives a display name from an ID, so "code-review" becomes
+// "Code Review"
+func titleFromID(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' })
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// splitTags splits a comma-separated list of tags
+func splitTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// quoteYAML quotes a string as a YAML double-quoted scalar. JSON strings are
+// valid YAML.
+func quoteYAML(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+func TestCreatePrompt(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "dev/existing.yaml", strings.Replace(validPrompt, "%s", "existing", 1))
+	writePromptFile(t, dir, "dev/broken.yaml", "metadata: [unclosed\n")
+	invalid := strings.Replace(validPrompt, "%s", "invalid", 1)
+	writePromptFile(t, dir, "dev/invalid.yaml", strings.Replace(invalid, `name: "Prompt"`, `name: ""`, 1))
+
+	s := scaffold{
+		ID:          "explain-trace",
+		Name:        "Explain Trace",
+		Description: "Explains a stack trace",
+		Author:      "Ada",
+		Category:    "debugging",
+		Tags:        []string{"errors"},
+		Created:     time.Date(2025, 8, 27, 10, 0, 0, 0, time.UTC),
+	}
+	for _, messages := range []bool{false, true} {
+		s.Messages = messages
+		s.ID = "explain-trace"
+		if messages {
+			s.ID = "explain-trace-chat"
+		}
+
+		path, err := createPrompt(dir, s)
+		if err != nil {
+			t.Fatalf("Failed to create prompt: %v", err)
+		}
+		if path != filepath.Join(dir, "debugging", s.ID+".yaml") {
+			t.Errorf("Expected prompt in category directory, got %s", path)
+		}
+
+		p, err := prompt.NewLoader(dir).LoadPrompt(path)
+		if err != nil {
+			t.Fatalf("Expected scaffold to load: %v", err)
+		}
+		if p.Metadata.Version != "0.1.0" || p.Metadata.Author != "Ada" || !p.Metadata.Created.Equal(s.Created) || len(p.Messages) > 0 != messages {
+			t.Errorf("Unexpected prompt: %+v", p)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(*scaffold)
+		expected string
+	}{
+		{"duplicate ID", func(s *scaffold) { s.ID = "existing" }, "already exists"},
+		{"duplicate ID of invalid prompt", func(s *scaffold) { s.ID = "invalid" }, "invalid.yaml, which currently fails to load"},
+		{"existing file", func(s *scaffold) {}, "already exists"},
+		{"invalid ID", func(s *scaffold) { s.ID = "two words" }, "id must contain only"},
+		{"invalid category", func(s *scaffold) { s.ID, s.Category = "other", prompt.PartialsDir }, "invalid category"},
+		{"missing author", func(s *scaffold) { s.ID, s.Author = "other", "" }, "author is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := s
+			invalid.ID, invalid.Messages = "explain-trace", false
+			tt.modify(&invalid)
+			if _, err := createPrompt(dir, invalid); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestAskMissing(t *testing.T) {
+	// The description is required, so an empty answer asks again
+	in := bufio.NewReader(strings.NewReader("code-review\n\n\nReviews code\ndev\nreview, quality\n"))
+	s := askMissing(in, io.Discard, scaffold{Author: "Ada"}, []string{"dev"})
+
+	if s.ID != "code-review" || s.Name != "Code Review" || s.Description != "Reviews code" || s.Author != "Ada" || s.Category != "dev" {
+		t.Errorf("Unexpected answers: %+v", s)
+	}
+	if strings.Join(s.Tags, ",") != "review,quality" {
+		t.Errorf("Expected tags review and quality, got %v", s.Tags)
+	}
+}
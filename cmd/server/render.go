@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/markopolo123/prompt-mcp/internal/server"
+	"github.com/markopolo123/prompt-mcp/internal/usage"
+)
+
+// argFlags collects repeated -arg key=value flags
+type argFlags map[string]string
+
+// String implements flag.Value
+func (a argFlags) String() string {
+	pairs := make([]string, 0, len(a))
+	for key, value := range a {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// Set implements flag.Value
+func (a argFlags) Set(pair string) error {
+	key, value, found := strings.Cut(pair, "=")
+	if !found || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+	a[strings.TrimSpace(key)] = value
+	return nil
+}
+
+// runRender implements the render subcommand
+func runRender(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	shared := addCommandFlags(flags)
+	argValues := argFlags{}
+	flags.Var(argValues, "arg", "Argument value as key=value; may be repeated")
+	argsFile := flags.String("args-file", "", "JSON file with an object of argument values; -arg values take precedence")
+	asJSON := flags.Bool("json", false, "Print the prompts/get result as JSON")
+	verbose := flags.Bool("v", false, "Show server log output")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s render [options] <id> [options]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Renders a prompt exactly as an MCP client would receive it from prompts/get.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return 2
+	}
+	id := positional[0]
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	arguments := map[string]string{}
+	if *argsFile != "" {
+		var err error
+		if arguments, err = readArgsFile(*argsFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	for key, value := range argValues {
+		arguments[key] = value
+	}
+
+	cfg, err := shared.config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Rendering from the command line is not a use of the prompt
+	cfg.Usage.Backend = usage.BackendNone
+	srv, err := server.NewServer(cfg.ServerConfig())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := srv.GetPrompt(context.Background(), id, arguments)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Print(server.FormatPromptResult(result))
+	return 0
+}
+
+// readArgsFile reads argument values from a JSON object. Values that are not
+// strings are passed as JSON text, as MCP clients send arguments as strings.
+func readArgsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read arguments file: %w", err)
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments file %s: %w", path, err)
+	}
+
+	arguments := make(map[string]string, len(values))
+	for key, value := range values {
+		if text, ok := value.(string); ok {
+			arguments[key] = text
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("argument '%s': %w", key, err)
+		}
+		arguments[key] = string(encoded)
+	}
+	return arguments, nil
+}
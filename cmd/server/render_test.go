@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/server"
+)
+
+func TestArgFlags(t *testing.T) {
+	args := argFlags{}
+	for _, pair := range []string{"name=Ada", "query=a=b", " lang =go"} {
+		if err := args.Set(pair); err != nil {
+			t.Errorf("Unexpected error for %q: %v", pair, err)
+		}
+	}
+	if args["name"] != "Ada" || args["query"] != "a=b" || args["lang"] != "go" {
+		t.Errorf("Unexpected arguments: %v", args)
+	}
+
+	for _, pair := range []string{"name", "=value"} {
+		if err := args.Set(pair); err == nil {
+			t.Errorf("Expected error for %q", pair)
+		}
+	}
+}
+
+func TestReadArgsFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writePromptFile(t, dir, "args.json", `{"name": "Ada", "files": ["a.go", "b.go"], "count": 3, "strict": true}`)
+
+	args, err := readArgsFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read arguments: %v", err)
+	}
+	expected := map[string]string{"name": "Ada", "files": `["a.go","b.go"]`, "count": "3", "strict": "true"}
+	for key, value := range expected {
+		if args[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, args[key])
+		}
+	}
+
+	invalid := writePromptFile(t, dir, "invalid.json", `["not", "an", "object"]`)
+	if _, err := readArgsFile(invalid); err == nil {
+		t.Error("Expected error for a JSON array")
+	}
+	if _, err := readArgsFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+const renderPrompt = `metadata:
+  id: "review"
+  name: "Review"
+  description: "Reviews code"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "code"
+    description: "Code to review"
+    type: "string"
+    required: true
+  - name: "language"
+    description: "Language of the code"
+    type: "enum"
+    values: ["go", "python"]
+    default: "go"
+
+prompt: |
+  Review this {{language}} code:
+  {{code}}
+`
+
+func TestRunRender(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "dev/review.yaml", renderPrompt)
+	defer log.SetOutput(os.Stderr)
+
+	status, out := captureStdout(t, func() int {
+		return runRender([]string{"review", "-prompts-dir", dir, "-arg", "code=x := 1", "-arg", "language=python"})
+	})
+	if expected := "Review this python code:\nx := 1\n"; status != 0 || out != expected {
+		t.Errorf("Expected status 0 and %q, got %d and %q", expected, status, out)
+	}
+
+	srv, err := server.NewServer(server.Config{Name: "test", Version: "0.0.0", PromptsDir: dir, EnablePrompts: true})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	// Failures report the server's own errors
+	failures := []map[string]string{
+		{"language": "go"},
+		{"code": "x", "language": "rust"},
+	}
+	for _, args := range failures {
+		_, serverErr := srv.GetPrompt(context.Background(), "review", args)
+		if serverErr == nil {
+			t.Fatalf("Expected server error for %v", args)
+		}
+
+		cmdArgs := []string{"-prompts-dir", dir, "review"}
+		for key, value := range args {
+			cmdArgs = append(cmdArgs, "-arg", key+"="+value)
+		}
+		status, errOut := captureStderr(t, func() int { return runRender(cmdArgs) })
+		if status != 1 || errOut != serverErr.Error()+"\n" {
+			t.Errorf("Expected status 1 and %q, got %d and %q", serverErr.Error(), status, errOut)
+		}
+	}
+}
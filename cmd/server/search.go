@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/markopolo123/prompt-mcp/internal/search"
+)
+
+// searchResult is a search match in JSON output
+type searchResult struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags,omitempty"`
+	Score       float64  `json:"score"`
+}
+
+// runSearch implements the search subcommand
+func runSearch(args []string) int {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	shared := addCommandFlags(flags)
+	limit := flags.Int("limit", 20, "Maximum number of results, 0 for no limit")
+	asJSON := flags.Bool("json", false, "Print results as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s search [options] <query>\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Words may be limited to a field: id, name, description, tag, author, category or body,")
+		fmt.Fprintln(flags.Output(), "as in 'review tag:testing author:team'. Typos are tolerated.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+	query := parseInterspersed(flags, args)
+
+	loader, err := shared.loader()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	library, err := loader.LoadAllPrompts()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	index := search.NewIndex(library, loader.GetCategoryFromPath)
+	matches, err := index.Search(strings.Join(query, " "), *limit)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	results := make([]searchResult, len(matches))
+	for i, match := range matches {
+		p := match.Prompt
+		results[i] = searchResult{
+			ID:          p.Metadata.ID,
+			Name:        p.Metadata.Name,
+			Description: p.Metadata.Description,
+			Category:    loader.GetCategoryFromPath(p.FilePath),
+			Tags:        p.Metadata.Tags,
+			Score:       match.Score,
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No matching prompts")
+		return 0
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "SCORE\tID\tCATEGORY\tDESCRIPTION")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%.2f\t%s\t%s\t%s\n", result.Score, result.ID, result.Category, result.Description)
+	}
+	writer.Flush()
+	return 0
+}
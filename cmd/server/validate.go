@@ -0,0 +1,292 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// Output formats of the validate command
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatGitHub = "github"
+	formatGitLab = "gitlab"
+)
+
+// validationReport is the JSON output of the validate command
+type validationReport struct {
+	Checked     int                 `json:"checked"`
+	Errors      int                 `json:"errors"`
+	Diagnostics []prompt.Diagnostic `json:"diagnostics"`
+}
+
+// runValidate implements the validate subcommand
+func runValidate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	shared := addCommandFlags(flags)
+	format := flags.String("format", formatText, "Output format: text, json, github or gitlab")
+	checkVersion := flags.Bool("check-version", false, "Also check that changed prompts bump their version, compared with -base")
+	base := flags.String("base", "HEAD", "Git revision to compare versions against with -check-version")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s validate [options] [directory | files...]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Validates every prompt in the prompts directory, or in the given directory, and")
+		fmt.Fprintln(flags.Output(), "reports all problems. With files, such as those changed in a pull request, only")
+		fmt.Fprintln(flags.Output(), "problems in those files are reported. Exits with status 1 if there are problems.")
+		fmt.Fprintln(flags.Output(), "\nOptions:")
+		flags.PrintDefaults()
+	}
+	files := parseInterspersed(flags, args)
+
+	switch *format {
+	case formatText, formatJSON, formatGitHub, formatGitLab:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid format %q, must be text, json, github or gitlab\n", *format)
+		return 2
+	}
+
+	cfg, err := shared.config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// A single directory argument replaces the configured prompts directory
+	promptsDir := cfg.Storage.PromptsDir
+	if len(files) == 1 {
+		if info, err := os.Stat(files[0]); err == nil && info.IsDir() {
+			promptsDir, files = files[0], nil
+		}
+	}
+
+	if !*checkVersion {
+		*base = ""
+	}
+	report := validate(promptsDir, files, *base)
+	if err := writeValidationReport(os.Stdout, *format, report); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// validate loads the whole prompts directory leniently, so that problems
+// with inheritance and duplicate IDs are found, and reports the problems in
+// files. An empty files list reports every problem. If base is not empty,
+// the versions of the prompts are also checked against that git revision.
+func validate(promptsDir string, files []string, base string) validationReport {
+	report := validationReport{Diagnostics: []prompt.Diagnostic{}}
+
+	if _, err := os.Stat(promptsDir); err != nil {
+		return report.add(prompt.Diagnostic{FilePath: promptsDir, Message: "prompts directory does not exist"})
+	}
+
+	loader := prompt.NewLoader(promptsDir)
+	loader.SetMode(prompt.LoadLenient)
+	library, err := loader.LoadAllPrompts()
+	if err != nil {
+		// Only partials can fail a lenient load
+		return report.add(prompt.Diagnostic{FilePath: filepath.Join(promptsDir, prompt.PartialsDir), Message: err.Error()})
+	}
+
+	include := func(string) bool { return true }
+	if len(files) == 0 {
+		failed := make(map[string]bool)
+		for _, diagnostic := range library.Diagnostics {
+			failed[diagnostic.FilePath] = true
+			report = report.add(diagnostic)
+		}
+		report.Checked = library.Len() + len(failed)
+	} else {
+		selected, checkAll, outside := selectFiles(promptsDir, files)
+		for _, file := range outside {
+			report = report.add(prompt.Diagnostic{FilePath: file, Message: fmt.Sprintf("file is not in the prompts directory %s", promptsDir)})
+		}
+		include = func(path string) bool { return checkAll || selected[absPath(path)] }
+		for _, diagnostic := range library.Diagnostics {
+			if include(diagnostic.FilePath) {
+				report = report.add(diagnostic)
+			}
+		}
+
+		report.Checked = len(selected) + len(outside)
+		if checkAll {
+			report.Checked = library.Len() + len(library.Diagnostics)
+		}
+	}
+
+	if base != "" {
+		for _, diagnostic := range checkVersions(promptsDir, library, base, include) {
+			report = report.add(diagnostic)
+		}
+	}
+	return report
+}
+
+// selectFiles picks the existing prompt files out of files, by absolute
+// path. Other files are ignored, except that a changed partial may break any
+// prompt, so checkAll is set. outside lists prompt files that are not in the
+// prompts directory.
+func selectFiles(promptsDir string, files []string) (selected map[string]bool, checkAll bool, outside []string) {
+	selected = make(map[string]bool)
+	root := absPath(promptsDir)
+	partials := filepath.Join(root, prompt.PartialsDir)
+
+	for _, file := range files {
+		path := absPath(file)
+		if _, err := os.Stat(path); err != nil {
+			continue // deleted files cannot be invalid
+		}
+
+		switch {
+		case strings.HasPrefix(path, partials+string(filepath.Separator)):
+			checkAll = checkAll || strings.EqualFold(filepath.Ext(path), prompt.PartialExt)
+		case !strings.EqualFold(filepath.Ext(path), ".yaml"):
+		case !strings.HasPrefix(path, root+string(filepath.Separator)):
+			outside = append(outside, file)
+		default:
+			selected[path] = true
+		}
+	}
+	return selected, checkAll, outside
+}
+
+// add appends a diagnostic to the report
+func (r validationReport) add(diagnostic prompt.Diagnostic) validationReport {
+	r.Diagnostics = append(r.Diagnostics, diagnostic)
+	r.Errors++
+	return r
+}
+
+// writeValidationReport writes the report in the given format, with file
+// paths relative to the working directory where possible
+func writeValidationReport(w io.Writer, format string, report validationReport) error {
+	for i := range report.Diagnostics {
+		report.Diagnostics[i].FilePath = displayPath(report.Diagnostics[i].FilePath)
+	}
+
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+
+	case formatGitHub:
+		for _, d := range report.Diagnostics {
+			writeGitHubCommand(w, "error", "Invalid prompt", d)
+		}
+		return nil
+
+	case formatGitLab:
+		// Code Quality report, shown in the merge request widget
+		issues := make([]gitLabIssue, len(report.Diagnostics))
+		for i, d := range report.Diagnostics {
+			issues[i] = newGitLabIssue("prompt-validation", d)
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(issues)
+
+	default:
+		for _, d := range report.Diagnostics {
+			fmt.Fprintln(w, d)
+		}
+		if report.Errors == 0 {
+			fmt.Fprintf(w, "%d prompt files checked, no problems found\n", report.Checked)
+			return nil
+		}
+		fmt.Fprintf(w, "%d prompt files checked, %d problems found\n", report.Checked, report.Errors)
+		return nil
+	}
+}
+
+// gitLabIssue is an entry of a GitLab Code Quality report
+type gitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitLabLocation `json:"location"`
+}
+
+// gitLabLocation is the location of a GitLab Code Quality issue
+type gitLabLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// newGitLabIssue converts a diagnostic found by checkName into a Code
+// Quality issue. The fingerprint identifies the issue across pipelines and
+// includes the line, so that repeated findings in one file stay distinct.
+func newGitLabIssue(checkName string, d prompt.Diagnostic) gitLabIssue {
+	sum := sha256.Sum256([]byte(strings.Join([]string{checkName, d.FilePath, strconv.Itoa(d.Line), d.Message}, "\x00")))
+
+	issue := gitLabIssue{
+		Description: d.Message,
+		CheckName:   checkName,
+		Fingerprint: hex.EncodeToString(sum[:]),
+		Severity:    "major",
+		Location:    gitLabLocation{Path: filepath.ToSlash(d.FilePath)},
+	}
+	issue.Location.Lines.Begin = max(d.Line, 1)
+	return issue
+}
+
+// writeGitHubCommand writes a diagnostic as a GitHub workflow command of the
+// given level, shown as an annotation on the pull request
+func writeGitHubCommand(w io.Writer, level, title string, d prompt.Diagnostic) {
+	properties := "file=" + escapeGitHubProperty(d.FilePath)
+	if d.Line > 0 {
+		properties += fmt.Sprintf(",line=%d", d.Line)
+	}
+	fmt.Fprintf(w, "::%s %s,title=%s::%s\n", level, properties, escapeGitHubProperty(title), escapeGitHubData(d.Message))
+}
+
+// escapeGitHubData escapes the message of a GitHub workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a GitHub workflow command
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// absPath returns the cleaned absolute form of path, or path itself if it
+// cannot be resolved
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+// displayPath returns path relative to the working directory if it lies
+// below it, as CI annotations expect repository-relative paths
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, absPath(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
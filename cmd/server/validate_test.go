@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+const validPrompt = `metadata:
+  id: "%s"
+  name: "Prompt"
+  description: "A test prompt"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+prompt: "Hello"
+`
+
+// writePromptFile writes content to dir/name, creating parent directories
+func writePromptFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "dev/good.yaml", strings.Replace(validPrompt, "%s", "good", 1))
+	broken := writePromptFile(t, dir, "dev/broken.yaml", "metadata: [unclosed\n")
+	invalid := writePromptFile(t, dir, "ops/invalid.yaml", strings.NewReplacer("%s", "invalid", `author: "test"`, `author: ""`, `name: "Prompt"`, `name: ""`).Replace(validPrompt))
+	writePromptFile(t, dir, "partials/footer.md", "Thanks\n")
+
+	report := validate(dir, nil, "")
+	if report.Checked != 3 || report.Errors != 3 {
+		t.Errorf("Expected 3 errors in 3 files, got %d errors in %d files: %v", report.Errors, report.Checked, report.Diagnostics)
+	}
+
+	// Only the given files are reported; other files are ignored
+	report = validate(dir, []string{invalid, filepath.Join(dir, "README.md"), filepath.Join(dir, "dev/deleted.yaml")}, "")
+	if report.Checked != 1 || report.Errors != 2 {
+		t.Errorf("Expected 2 errors in the invalid file, got %v", report.Diagnostics)
+	}
+	for _, diagnostic := range report.Diagnostics {
+		if diagnostic.FilePath != invalid {
+			t.Errorf("Expected only diagnostics for %s, got %v", invalid, diagnostic)
+		}
+	}
+
+	// A changed partial may break any prompt
+	report = validate(dir, []string{filepath.Join(dir, "partials/footer.md")}, "")
+	if report.Errors != 3 {
+		t.Errorf("Expected every problem to be reported for a changed partial, got %v", report.Diagnostics)
+	}
+
+	outside := writePromptFile(t, t.TempDir(), "other.yaml", "")
+	report = validate(dir, []string{broken, outside}, "")
+	if report.Errors != 2 || !strings.Contains(report.Diagnostics[0].Message, "not in the prompts directory") {
+		t.Errorf("Expected files outside the prompts directory to be reported, got %v", report.Diagnostics)
+	}
+
+	if report := validate(filepath.Join(dir, "missing"), nil, ""); report.Errors != 1 {
+		t.Errorf("Expected a missing prompts directory to be reported, got %v", report.Diagnostics)
+	}
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	report := validationReport{Checked: 2}
+	report = report.add(prompt.Diagnostic{FilePath: "prompts/a,b.yaml", Line: 3, Message: "100% broken\nreally"})
+	report = report.add(prompt.Diagnostic{FilePath: "prompts/c.yaml", Message: "invalid"})
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{formatText, "prompts/a,b.yaml:3: 100% broken\nreally\nprompts/c.yaml: invalid\n2 prompt files checked, 2 problems found\n"},
+		{formatGitHub, "::error file=prompts/a%2Cb.yaml,line=3,title=Invalid prompt::100%25 broken%0Areally\n::error file=prompts/c.yaml,title=Invalid prompt::invalid\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := writeValidationReport(&out, tt.format, report); err != nil {
+			t.Fatalf("Failed to write %s report: %v", tt.format, err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("Expected %s output %q, got %q", tt.format, tt.expected, out.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeValidationReport(&out, formatGitLab, report); err != nil {
+		t.Fatalf("Failed to write gitlab report: %v", err)
+	}
+	var issues []gitLabIssue
+	if err := json.Unmarshal(out.Bytes(), &issues); err != nil {
+		t.Fatalf("Failed to decode gitlab report: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Location.Lines.Begin != 3 || issues[1].Location.Lines.Begin != 1 || issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Errorf("Unexpected gitlab report: %+v", issues)
+	}
+}
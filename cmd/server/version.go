@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// versionLinePattern finds the metadata version in a prompt file
+var versionLinePattern = regexp.MustCompile(`^\s+version:`)
+
+// checkVersions compares the prompt files selected by include with their
+// definitions at the git revision base, and reports prompts whose changes
+// need a version bump. Prompts that are new since base are not checked.
+func checkVersions(promptsDir string, library *prompt.PromptLibrary, base string, include func(string) bool) []prompt.Diagnostic {
+	if err := exec.Command("git", "-C", promptsDir, "rev-parse", "--verify", "--quiet", base+"^{commit}").Run(); err != nil {
+		return []prompt.Diagnostic{{FilePath: promptsDir, Message: fmt.Sprintf("cannot check versions: '%s' is not a git revision", base)}}
+	}
+
+	prompts := library.ListPrompts()
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].FilePath < prompts[j].FilePath })
+
+	var diagnostics []prompt.Diagnostic
+	for _, p := range prompts {
+		if !include(p.FilePath) {
+			continue
+		}
+
+		previous, found := gitDefinition(base, p.FilePath)
+		if !found {
+			continue
+		}
+
+		data, err := os.ReadFile(p.FilePath)
+		if err != nil {
+			diagnostics = append(diagnostics, prompt.Diagnostic{FilePath: p.FilePath, PromptID: p.Metadata.ID, Message: err.Error()})
+			continue
+		}
+		var current prompt.Prompt
+		if err := yaml.Unmarshal(data, &current); err != nil {
+			continue // already reported by validation
+		}
+
+		if err := prompt.CheckVersionChange(previous, &current); err != nil {
+			diagnostics = append(diagnostics, prompt.Diagnostic{
+				FilePath: p.FilePath,
+				PromptID: p.Metadata.ID,
+				Line:     versionLine(data),
+				Message:  err.Error(),
+			})
+		}
+	}
+	return diagnostics
+}
+
+// gitDefinition reads the definition of a prompt file as written at a git
+// revision. found is false if the file did not exist or did not parse.
+func gitDefinition(revision, filePath string) (p *prompt.Prompt, found bool) {
+	// A "./" path is resolved against the directory given with -C
+	spec := revision + ":./" + filepath.Base(filePath)
+	data, err := exec.Command("git", "-C", filepath.Dir(filePath), "show", spec).Output()
+	if err != nil {
+		return nil, false
+	}
+
+	var previous prompt.Prompt
+	if err := yaml.Unmarshal(data, &previous); err != nil {
+		return nil, false
+	}
+	return &previous, true
+}
+
+// versionLine returns the line of the metadata version in a prompt file, or
+// zero if it is not found
+func versionLine(data []byte) int {
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if versionLinePattern.Match(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// gitRun runs a git command in dir
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestCheckVersions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	withArgument := strings.Replace(validPrompt, `prompt: "Hello"`, `arguments:
+  - name: "name"
+    description: "Who to greet"
+    type: "string"
+    required: false
+
+prompt: "Hello {{name}}"`, 1)
+	changed := writePromptFile(t, dir, "dev/changed.yaml", strings.Replace(withArgument, "%s", "changed", 1))
+	breaking := writePromptFile(t, dir, "dev/breaking.yaml", strings.Replace(withArgument, "%s", "breaking", 1))
+	writePromptFile(t, dir, "dev/same.yaml", strings.Replace(validPrompt, "%s", "same", 1))
+
+	if report := validate(dir, nil, "HEAD"); report.Errors != 1 || !strings.Contains(report.Diagnostics[0].Message, "not a git revision") {
+		t.Errorf("Expected a missing revision to be reported, got %v", report.Diagnostics)
+	}
+
+	gitRun(t, dir, "init", "-q")
+	gitRun(t, dir, "add", ".")
+	gitRun(t, dir, "commit", "-q", "-m", "Add prompts")
+
+	writePromptFile(t, dir, "dev/changed.yaml", strings.NewReplacer("%s", "changed", "Hello {{name}}", "Hi {{name}}").Replace(withArgument))
+	writePromptFile(t, dir, "dev/breaking.yaml", strings.NewReplacer("%s", "breaking", "required: false", "required: true", `version: "1.0.0"`, `version: "1.1.0"`).Replace(withArgument))
+	writePromptFile(t, dir, "dev/new.yaml", strings.Replace(validPrompt, "%s", "new", 1))
+
+	report := validate(dir, nil, "HEAD")
+	if report.Errors != 2 {
+		t.Fatalf("Expected 2 version problems, got %v", report.Diagnostics)
+	}
+	if d := report.Diagnostics[0]; d.FilePath != breaking || d.Line != 8 || !strings.Contains(d.Message, "argument 'name' became required") {
+		t.Errorf("Expected breaking change to need a major bump, got %v", d)
+	}
+	if d := report.Diagnostics[1]; d.FilePath != changed || !strings.Contains(d.Message, "without a version bump from 1.0.0") {
+		t.Errorf("Expected body change to need a bump, got %v", d)
+	}
+
+	// Only the given files are checked
+	if report := validate(dir, []string{breaking}, "HEAD"); report.Errors != 1 {
+		t.Errorf("Expected only the breaking change, got %v", report.Diagnostics)
+	}
+
+	writePromptFile(t, dir, "dev/changed.yaml", strings.NewReplacer("%s", "changed", "Hello {{name}}", "Hi {{name}}", `version: "1.0.0"`, `version: "1.0.1"`).Replace(withArgument))
+	writePromptFile(t, dir, "dev/breaking.yaml", strings.NewReplacer("%s", "breaking", "required: false", "required: true", `version: "1.0.0"`, `version: "2.0.0"`).Replace(withArgument))
+	if report := validate(dir, nil, "HEAD"); report.Errors != 0 {
+		t.Errorf("Expected bumped versions to pass, got %v", report.Diagnostics)
+	}
+}
@@ -3,7 +3,8 @@ module github.com/markopolo123/prompt-mcp
 go 1.24.1
 
 require (
-	github.com/mark3labs/mcp-go v0.38.0
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/mark3labs/mcp-go v0.48.0
 	github.com/modelcontextprotocol/go-sdk v0.3.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -11,11 +12,12 @@ require (
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
-	github.com/google/jsonschema-go v0.2.0 // indirect
+	github.com/google/jsonschema-go v0.4.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/invopop/jsonschema v0.13.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
@@ -0,0 +1,328 @@
+// Package config loads server configuration.
+//
+// Settings are resolved in the following order, each step overriding the
+// previous one:
+//
+//  1. built-in defaults (see Default)
+//  2. the YAML configuration file, if one is given
+//  3. PROMPT_MCP_* environment variables (see ApplyEnv)
+//  4. command line flags that were explicitly set
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markopolo123/prompt-mcp/internal/lint"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"github.com/markopolo123/prompt-mcp/internal/server"
+	"github.com/markopolo123/prompt-mcp/internal/storage"
+	"github.com/markopolo123/prompt-mcp/internal/usage"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix shared by all configuration environment variables
+const EnvPrefix = "PROMPT_MCP_"
+
+// Config is the typed form of config/server.yaml
+type Config struct {
+	Server  ServerSection  `yaml:"server"`
+	Storage StorageSection `yaml:"storage"`
+	MCP     MCPSection     `yaml:"mcp"`
+	Usage   UsageSection   `yaml:"usage"`
+	Embed   EmbedSection   `yaml:"embed"`
+	Lint    LintSection    `yaml:"lint"`
+}
+
+// ServerSection holds server identity settings
+type ServerSection struct {
+	Name       string `yaml:"name"`
+	Version    string `yaml:"version"`
+	Transport  string `yaml:"transport"`
+	ListenAddr string `yaml:"listen_addr"`
+}
+
+// StorageSection holds prompt storage settings
+type StorageSection struct {
+	PromptsDir    string        `yaml:"prompts_dir"`
+	WatchChanges  bool          `yaml:"watch_changes"`
+	WatchDebounce time.Duration `yaml:"watch_debounce"`
+	LoadMode      string        `yaml:"load_mode"`
+}
+
+// UsageSection holds usage statistics settings
+type UsageSection struct {
+	Backend       string        `yaml:"backend"`
+	Path          string        `yaml:"path"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+// EmbedSection holds settings for files embedded in prompt messages
+type EmbedSection struct {
+	Root     string `yaml:"root"`
+	MaxBytes int64  `yaml:"max_bytes"`
+}
+
+// LintSection holds the rules of the lint command
+type LintSection struct {
+	Rules                map[string]string `yaml:"rules"` // severity of each rule: off, warning or error
+	MinDescriptionLength int               `yaml:"min_description_length"`
+	MaxTokens            int               `yaml:"max_tokens"`
+}
+
+// MCPSection holds MCP protocol settings
+type MCPSection struct {
+	Capabilities Capabilities `yaml:"capabilities"`
+}
+
+// Capabilities toggles the MCP features offered to clients
+type Capabilities struct {
+	Prompts   bool `yaml:"prompts"`
+	Resources bool `yaml:"resources"`
+	Tools     bool `yaml:"tools"`
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	return Config{
+		Server: ServerSection{
+			Name:       "team-prompt-server",
+			Version:    "1.0.0",
+			Transport:  server.TransportStdio,
+			ListenAddr: server.DefaultListenAddr,
+		},
+		Storage: StorageSection{
+			PromptsDir:    "./prompts",
+			WatchDebounce: storage.DefaultDebounce,
+			LoadMode:      string(prompt.LoadStrict),
+		},
+		MCP: MCPSection{
+			Capabilities: Capabilities{
+				Prompts:   true,
+				Resources: true,
+			},
+		},
+		Usage: UsageSection{
+			Backend:       usage.BackendJSON,
+			FlushInterval: usage.DefaultFlushInterval,
+		},
+		Embed: EmbedSection{
+			MaxBytes: server.DefaultEmbedMaxBytes,
+		},
+		Lint: LintSection{
+			MinDescriptionLength: lint.DefaultMinDescriptionLength,
+			MaxTokens:            lint.DefaultMaxTokens,
+		},
+	}
+}
+
+// Load returns the defaults overlaid with the file at path (if path is not
+// empty) and then with the process environment
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := LoadFile(path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := ApplyEnv(&cfg, os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// LoadFile overlays the YAML file at path onto cfg. Keys that are not
+// part of the configuration schema are rejected.
+func LoadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ApplyEnv overlays PROMPT_MCP_* environment variables onto cfg using lookup
+func ApplyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	stringVars := map[string]*string{
+		"NAME":          &cfg.Server.Name,
+		"VERSION":       &cfg.Server.Version,
+		"TRANSPORT":     &cfg.Server.Transport,
+		"LISTEN_ADDR":   &cfg.Server.ListenAddr,
+		"PROMPTS_DIR":   &cfg.Storage.PromptsDir,
+		"LOAD_MODE":     &cfg.Storage.LoadMode,
+		"USAGE_BACKEND": &cfg.Usage.Backend,
+		"USAGE_PATH":    &cfg.Usage.Path,
+		"EMBED_ROOT":    &cfg.Embed.Root,
+	}
+	for key, target := range stringVars {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			*target = value
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"WATCH_CHANGES":          &cfg.Storage.WatchChanges,
+		"CAPABILITIES_PROMPTS":   &cfg.MCP.Capabilities.Prompts,
+		"CAPABILITIES_RESOURCES": &cfg.MCP.Capabilities.Resources,
+		"CAPABILITIES_TOOLS":     &cfg.MCP.Capabilities.Tools,
+	}
+	for key, target := range boolVars {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean for %s%s: %q", EnvPrefix, key, value)
+			}
+			*target = parsed
+		}
+	}
+
+	if value, ok := lookup(EnvPrefix + "WATCH_DEBOUNCE"); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration for %sWATCH_DEBOUNCE: %q", EnvPrefix, value)
+		}
+		cfg.Storage.WatchDebounce = parsed
+	}
+
+	if value, ok := lookup(EnvPrefix + "EMBED_MAX_BYTES"); ok {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer for %sEMBED_MAX_BYTES: %q", EnvPrefix, value)
+		}
+		cfg.Embed.MaxBytes = parsed
+	}
+
+	return nil
+}
+
+// Validate checks that the resolved configuration is usable
+func (c Config) Validate() error {
+	if strings.TrimSpace(c.Server.Name) == "" {
+		return errors.New("server.name is required")
+	}
+
+	if strings.TrimSpace(c.Server.Version) == "" {
+		return errors.New("server.version is required")
+	}
+
+	if !server.IsValidTransport(c.Server.Transport) {
+		return fmt.Errorf("server.transport must be one of %s, %s or %s, got %q",
+			server.TransportStdio, server.TransportSSE, server.TransportStreamableHTTP, c.Server.Transport)
+	}
+
+	if c.Server.Transport != server.TransportStdio && strings.TrimSpace(c.Server.ListenAddr) == "" {
+		return errors.New("server.listen_addr is required for HTTP transports")
+	}
+
+	if strings.TrimSpace(c.Storage.PromptsDir) == "" {
+		return errors.New("storage.prompts_dir is required")
+	}
+
+	if c.Storage.WatchDebounce < 0 {
+		return errors.New("storage.watch_debounce must not be negative")
+	}
+
+	if !prompt.IsValidLoadMode(c.Storage.LoadMode) {
+		return fmt.Errorf("storage.load_mode must be %s or %s, got %q",
+			prompt.LoadStrict, prompt.LoadLenient, c.Storage.LoadMode)
+	}
+
+	if !usage.IsValidBackend(c.Usage.Backend) {
+		return fmt.Errorf("usage.backend must be one of %s, %s or %s, got %q",
+			usage.BackendNone, usage.BackendJSON, usage.BackendEventLog, c.Usage.Backend)
+	}
+
+	if c.Embed.MaxBytes <= 0 {
+		return errors.New("embed.max_bytes must be positive")
+	}
+
+	rules := make([]string, 0, len(c.Lint.Rules))
+	for rule := range c.Lint.Rules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		if !lint.IsValidRule(rule) {
+			return fmt.Errorf("lint.rules has unknown rule %q, must be one of %s", rule, strings.Join(lint.RuleNames(), ", "))
+		}
+		if !lint.IsValidSeverity(c.Lint.Rules[rule]) {
+			return fmt.Errorf("lint.rules.%s must be %s, %s or %s, got %q",
+				rule, lint.SeverityOff, lint.SeverityWarning, lint.SeverityError, c.Lint.Rules[rule])
+		}
+	}
+
+	if c.Lint.MinDescriptionLength < 0 {
+		return errors.New("lint.min_description_length must not be negative")
+	}
+
+	if c.Lint.MaxTokens <= 0 {
+		return errors.New("lint.max_tokens must be positive")
+	}
+
+	return nil
+}
+
+// ServerConfig converts the configuration into a server.Config
+func (c Config) ServerConfig() server.Config {
+	return server.Config{
+		Name:            c.Server.Name,
+		Version:         c.Server.Version,
+		Transport:       c.Server.Transport,
+		ListenAddr:      c.Server.ListenAddr,
+		PromptsDir:      c.Storage.PromptsDir,
+		WatchChanges:    c.Storage.WatchChanges,
+		WatchDebounce:   c.Storage.WatchDebounce,
+		LoadMode:        prompt.LoadMode(c.Storage.LoadMode),
+		EnablePrompts:   c.MCP.Capabilities.Prompts,
+		EnableResources: c.MCP.Capabilities.Resources,
+		EnableTools:     c.MCP.Capabilities.Tools,
+		EmbedRoot:       c.Embed.Root,
+		EmbedMaxBytes:   c.Embed.MaxBytes,
+		Usage: usage.Config{
+			Backend:       c.Usage.Backend,
+			Path:          c.Usage.Path,
+			FlushInterval: c.Usage.FlushInterval,
+		},
+	}
+}
+
+// StorageConfig converts the configuration into a storage.Config
+func (c Config) StorageConfig() storage.Config {
+	return storage.Config{
+		PromptsDir:   c.Storage.PromptsDir,
+		WatchChanges: c.Storage.WatchChanges,
+		Debounce:     c.Storage.WatchDebounce,
+		LoadMode:     prompt.LoadMode(c.Storage.LoadMode),
+	}
+}
+
+// LintConfig converts the configuration into a lint.Config
+func (c Config) LintConfig() lint.Config {
+	rules := make(map[string]lint.Severity, len(c.Lint.Rules))
+	for rule, severity := range c.Lint.Rules {
+		rules[rule] = lint.Severity(severity)
+	}
+	return lint.Config{
+		Rules:                rules,
+		MinDescriptionLength: c.Lint.MinDescriptionLength,
+		MaxTokens:            c.Lint.MaxTokens,
+	}
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadShippedConfig(t *testing.T) {
+	cfg, err := Load("../../config/server.yaml")
+	if err != nil {
+		t.Fatalf("Failed to load shipped config: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Shipped config should be valid: %v", err)
+	}
+
+	if !cfg.MCP.Capabilities.Prompts {
+		t.Error("Expected prompts capability to be enabled")
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "server.yaml")
+	content := `server:
+  name: "from-file"
+storage:
+  prompts_dir: "/file/prompts"
+  watch_debounce: 2s
+mcp:
+  capabilities:
+    tools: true
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := Default()
+	if err := LoadFile(configFile, &cfg); err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+
+	env := map[string]string{
+		"PROMPT_MCP_PROMPTS_DIR":   "/env/prompts",
+		"PROMPT_MCP_WATCH_CHANGES": "true",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	if err := ApplyEnv(&cfg, lookup); err != nil {
+		t.Fatalf("Failed to apply environment: %v", err)
+	}
+
+	// Default survives when neither file nor env set it
+	if cfg.Server.Version != "1.0.0" {
+		t.Errorf("Expected default version 1.0.0, got %s", cfg.Server.Version)
+	}
+
+	// File overrides default
+	if cfg.Server.Name != "from-file" {
+		t.Errorf("Expected name from file, got %s", cfg.Server.Name)
+	}
+	if cfg.Storage.WatchDebounce != 2*time.Second {
+		t.Errorf("Expected debounce 2s from file, got %s", cfg.Storage.WatchDebounce)
+	}
+	if !cfg.MCP.Capabilities.Tools || !cfg.MCP.Capabilities.Prompts {
+		t.Errorf("Expected tools from file and prompts from defaults, got %+v", cfg.MCP.Capabilities)
+	}
+
+	// Environment overrides file
+	if cfg.Storage.PromptsDir != "/env/prompts" {
+		t.Errorf("Expected prompts dir from env, got %s", cfg.Storage.PromptsDir)
+	}
+	if !cfg.Storage.WatchChanges {
+		t.Error("Expected watch_changes from env")
+	}
+
+	serverConfig := cfg.ServerConfig()
+	if serverConfig.PromptsDir != "/env/prompts" || !serverConfig.EnableTools {
+		t.Errorf("Server config not filled from resolved config: %+v", serverConfig)
+	}
+}
+
+func TestUnknownKeysRejected(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "server.yaml")
+	content := `storage:
+  prompt_dir: "./prompts"
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg := Default()
+	err := LoadFile(configFile, &cfg)
+	if err == nil {
+		t.Fatal("Expected unknown key to be rejected")
+	}
+	if !strings.Contains(err.Error(), "prompt_dir") {
+		t.Errorf("Expected error to name the unknown key, got: %v", err)
+	}
+}
+
+func TestInvalidEnvValue(t *testing.T) {
+	cfg := Default()
+	lookup := func(key string) (string, bool) {
+		if key == "PROMPT_MCP_CAPABILITIES_TOOLS" {
+			return "maybe", true
+		}
+		return "", false
+	}
+
+	if err := ApplyEnv(&cfg, lookup); err == nil {
+		t.Error("Expected invalid boolean to be rejected")
+	}
+}
+
+func TestValidateTransport(t *testing.T) {
+	cfg := Default()
+	cfg.Server.Transport = "websocket"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown transport to be rejected")
+	}
+
+	cfg.Server.Transport = "sse"
+	cfg.Server.ListenAddr = ""
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected HTTP transport without listen address to be rejected")
+	}
+}
+
+func TestEmbedSettings(t *testing.T) {
+	cfg := Default()
+	if cfg.Embed.Root != "" {
+		t.Errorf("Expected file embedding to be disabled by default, got root %q", cfg.Embed.Root)
+	}
+
+	env := map[string]string{
+		"PROMPT_MCP_EMBED_ROOT":      "/src",
+		"PROMPT_MCP_EMBED_MAX_BYTES": "4096",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	if err := ApplyEnv(&cfg, lookup); err != nil {
+		t.Fatalf("Failed to apply environment: %v", err)
+	}
+
+	serverConfig := cfg.ServerConfig()
+	if serverConfig.EmbedRoot != "/src" || serverConfig.EmbedMaxBytes != 4096 {
+		t.Errorf("Expected embed settings from env, got root %q and limit %d", serverConfig.EmbedRoot, serverConfig.EmbedMaxBytes)
+	}
+
+	cfg.Embed.MaxBytes = 0
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected non-positive embed.max_bytes to be rejected")
+	}
+}
+
+func TestLoadMode(t *testing.T) {
+	cfg := Default()
+	if cfg.Storage.LoadMode != "strict" {
+		t.Errorf("Expected strict loading by default, got %q", cfg.Storage.LoadMode)
+	}
+
+	lookup := func(key string) (string, bool) {
+		if key == "PROMPT_MCP_LOAD_MODE" {
+			return "lenient", true
+		}
+		return "", false
+	}
+	if err := ApplyEnv(&cfg, lookup); err != nil {
+		t.Fatalf("Failed to apply environment: %v", err)
+	}
+	if cfg.ServerConfig().LoadMode != "lenient" || cfg.StorageConfig().LoadMode != "lenient" {
+		t.Errorf("Expected lenient loading from env, got %q", cfg.Storage.LoadMode)
+	}
+
+	cfg.Storage.LoadMode = "forgiving"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown load mode to be rejected")
+	}
+}
+
+func TestLintSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "server.yaml")
+	content := `lint:
+  rules:
+    missing-tags: error
+    id-case: off
+  max_tokens: 500
+`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid lint settings: %v", err)
+	}
+
+	lintConfig := cfg.LintConfig()
+	if lintConfig.Rules["missing-tags"] != "error" || lintConfig.Rules["id-case"] != "off" {
+		t.Errorf("Expected rule severities from file, got %v", lintConfig.Rules)
+	}
+	if lintConfig.MaxTokens != 500 || lintConfig.MinDescriptionLength != 20 {
+		t.Errorf("Expected max tokens from file and default description length, got %d and %d", lintConfig.MaxTokens, lintConfig.MinDescriptionLength)
+	}
+
+	cfg.Lint.Rules["no-such-rule"] = "error"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown rule") {
+		t.Errorf("Expected unknown rule to be rejected, got %v", err)
+	}
+
+	delete(cfg.Lint.Rules, "no-such-rule")
+	cfg.Lint.Rules["missing-tags"] = "fatal"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected unknown severity to be rejected")
+	}
+}
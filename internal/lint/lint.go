@@ -0,0 +1,250 @@
+// Package lint checks prompts for quality problems that schema validation
+// does not catch, such as unused optional arguments or missing tags. Each
+// rule reports problems at a configurable severity, and rules can be
+// disabled in a prompt file with "# lint-disable" comments.
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is the level at which a rule reports problems
+type Severity string
+
+const (
+	// SeverityOff disables a rule
+	SeverityOff Severity = "off"
+	// SeverityWarning reports problems without failing the lint
+	SeverityWarning Severity = "warning"
+	// SeverityError reports problems that fail the lint
+	SeverityError Severity = "error"
+)
+
+// IsValidSeverity reports whether severity names a supported severity
+func IsValidSeverity(severity string) bool {
+	switch Severity(severity) {
+	case SeverityOff, SeverityWarning, SeverityError:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defaults of the rule settings
+const (
+	DefaultMinDescriptionLength = 20
+	DefaultMaxTokens            = 2000
+)
+
+// Config holds the rule settings
+type Config struct {
+	Rules                map[string]Severity // overrides of the default severity of each rule
+	MinDescriptionLength int                 // minimum description length in characters
+	MaxTokens            int                 // token budget of the prompt body
+}
+
+// DefaultConfig returns the default rule settings
+func DefaultConfig() Config {
+	return Config{
+		MinDescriptionLength: DefaultMinDescriptionLength,
+		MaxTokens:            DefaultMaxTokens,
+	}
+}
+
+// Problem is a rule violation found in a prompt file
+type Problem struct {
+	prompt.Diagnostic
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+}
+
+// String formats the problem as "file:line: severity: message (rule)"
+func (p Problem) String() string {
+	d := p.Diagnostic
+	d.Message = fmt.Sprintf("%s: %s (%s)", p.Severity, p.Message, p.Rule)
+	return d.String()
+}
+
+// Linter checks prompts against the enabled rules
+type Linter struct {
+	config     Config
+	severities map[string]Severity
+}
+
+// New creates a linter with the given settings
+func New(config Config) *Linter {
+	severities := make(map[string]Severity, len(rules))
+	for name, r := range rules {
+		severities[name] = r.severity
+		if severity, exists := config.Rules[name]; exists {
+			severities[name] = severity
+		}
+	}
+	return &Linter{config: config, severities: severities}
+}
+
+// Lint checks a loaded prompt against the enabled rules. source is the
+// content of the prompt file, used for line numbers and lint-disable
+// comments.
+func (l *Linter) Lint(p *prompt.Prompt, source []byte) []Problem {
+	src := parseSource(source)
+
+	var problems []Problem
+	for _, name := range RuleNames() {
+		severity := l.severities[name]
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, f := range rules[name].check(l.config, p, src) {
+			if src.disabled(name, f.line) {
+				continue
+			}
+			problems = append(problems, Problem{
+				Diagnostic: prompt.Diagnostic{FilePath: p.FilePath, PromptID: p.Metadata.ID, Line: f.line, Message: f.message},
+				Rule:       name,
+				Severity:   severity,
+			})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// disablePattern matches a lint-disable comment and the rules it names
+var disablePattern = regexp.MustCompile(`^#\s*lint-disable(?:\s+(.*))?$`)
+
+// source is a parsed prompt file
+type source struct {
+	lines  []string
+	root   *yaml.Node              // top-level mapping, nil if the file does not parse
+	file   map[string]bool         // rules disabled in the whole file; "" disables all
+	byLine map[int]map[string]bool // rules disabled on a single line
+}
+
+// parseSource parses a prompt file for locations and lint-disable comments.
+// A comment on a line of its own disables rules in the whole file, and a
+// comment at the end of a line disables them on that line.
+func parseSource(data []byte) *source {
+	src := &source{
+		lines:  strings.Split(string(data), "\n"),
+		file:   make(map[string]bool),
+		byLine: make(map[int]map[string]bool),
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil || len(document.Content) == 0 {
+		return src
+	}
+	if root := document.Content[0]; root.Kind == yaml.MappingNode {
+		src.root = root
+	}
+
+	var walk func(node *yaml.Node)
+	walk = func(node *yaml.Node) {
+		for _, comment := range []string{node.HeadComment, node.FootComment} {
+			addDirectives(comment, src.file)
+		}
+		if node.LineComment != "" {
+			if src.byLine[node.Line] == nil {
+				src.byLine[node.Line] = make(map[string]bool)
+			}
+			addDirectives(node.LineComment, src.byLine[node.Line])
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(&document)
+
+	return src
+}
+
+// addDirectives adds the rules named by lint-disable comments to disabled
+func addDirectives(comment string, disabled map[string]bool) {
+	for _, line := range strings.Split(comment, "\n") {
+		match := disablePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		names := strings.FieldsFunc(match[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(names) == 0 {
+			disabled[""] = true
+		}
+		for _, name := range names {
+			disabled[name] = true
+		}
+	}
+}
+
+// disabled reports whether a rule is disabled for a problem on line
+func (s *source) disabled(rule string, line int) bool {
+	if s.file[""] || s.file[rule] {
+		return true
+	}
+	return line > 0 && (s.byLine[line][""] || s.byLine[line][rule])
+}
+
+// lookup returns the node at a path of mapping keys, or nil
+func (s *source) lookup(path ...string) (key, value *yaml.Node) {
+	node := s.root
+	for _, name := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		key, value = nil, nil
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == name {
+				key, value = node.Content[i], node.Content[i+1]
+				break
+			}
+		}
+		node = value
+	}
+	return key, value
+}
+
+// line returns the line of the key at path, falling back to the closest
+// enclosing key, or zero if none is found
+func (s *source) line(path ...string) int {
+	for i := len(path); i > 0; i-- {
+		if key, _ := s.lookup(path[:i]...); key != nil {
+			return key.Line
+		}
+	}
+	return 0
+}
+
+// argumentLine returns the line of the argument with the given name
+func (s *source) argumentLine(name string) int {
+	_, arguments := s.lookup("arguments")
+	if arguments == nil || arguments.Kind != yaml.SequenceNode {
+		return 0
+	}
+	for _, item := range arguments.Content {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Value == name {
+				return item.Line
+			}
+		}
+	}
+	return 0
+}
+
+// bodyLine returns the line of the prompt or messages key
+func (s *source) bodyLine() int {
+	for _, field := range []string{"prompt", "messages"} {
+		if key, _ := s.lookup(field); key != nil {
+			return key.Line
+		}
+	}
+	return 0
+}
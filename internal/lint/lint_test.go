@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+const cleanPrompt = `metadata:
+  id: "code-review"
+  name: "Code Review"
+  description: "Reviews code for quality and security"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-28T10:00:00Z"
+  version: "1.0.0"
+  tags: ["review"]
+
+arguments:
+  - name: "language"
+    description: "Language of the code"
+    type: "string"
+    required: false
+
+prompt: |
+  Review the following {{language}} code.
+`
+
+// messyPrompt breaks every rule but the token budget
+var messyPrompt = strings.Replace(`metadata:
+  id: "Code_Review"
+  name: "Code Review"
+  description: "Reviews code"
+  author: "test"
+  created: "2025-08-28T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "language"
+    description: "Language of the code"
+    type: "string"
+    required: false
+  - name: "focus"
+    description: "What to focus on"
+    type: "string"
+    required: false
+
+prompt: |
+  Review the code.
+`, "Review the code.", "Review the code.  ", 1)
+
+// lintFile loads a prompt file and lints it
+func lintFile(t *testing.T, linter *Linter, name, content string) []Problem {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	p, err := prompt.NewLoader(dir).LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("Failed to load prompt: %v", err)
+	}
+	p.FilePath = path
+	return linter.Lint(p, []byte(content))
+}
+
+// formatProblems formats problems without their file path
+func formatProblems(problems []Problem) string {
+	var parts []string
+	for _, p := range problems {
+		parts = append(parts, strings.TrimPrefix(p.String(), p.FilePath+":"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func TestLint(t *testing.T) {
+	linter := New(DefaultConfig())
+
+	if problems := lintFile(t, linter, "code-review.yaml", cleanPrompt); len(problems) != 0 {
+		t.Errorf("Expected no problems, got:\n%s", formatProblems(problems))
+	}
+
+	problems := lintFile(t, linter, "review.yaml", messyPrompt)
+	expected := []struct {
+		rule     string
+		line     int
+		severity Severity
+	}{
+		{RuleMissingTags, 1, SeverityWarning},
+		{RuleFilenameMismatch, 2, SeverityWarning},
+		{RuleIDCase, 2, SeverityWarning},
+		{RuleShortDescription, 4, SeverityWarning},
+		{RuleModifiedBeforeCreated, 7, SeverityError},
+		{RuleUnusedArgument, 11, SeverityWarning},
+		{RuleUnusedArgument, 15, SeverityWarning},
+		{RuleTrailingWhitespace, 21, SeverityWarning},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got:\n%s", len(expected), formatProblems(problems))
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Rule != want.rule || got.Line != want.line || got.Severity != want.severity {
+			t.Errorf("Problem %d: expected %s at line %d (%s), got %s at line %d (%s)", i, want.rule, want.line, want.severity, got.Rule, got.Line, got.Severity)
+		}
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	config := DefaultConfig()
+	config.Rules = map[string]Severity{RuleMissingTags: SeverityError, RuleIDCase: SeverityOff}
+	config.MinDescriptionLength = 5
+	config.MaxTokens = 2
+
+	problems := lintFile(t, New(config), "Code_Review.yaml", messyPrompt)
+	rules := make(map[string]Severity)
+	for _, p := range problems {
+		rules[p.Rule] = p.Severity
+	}
+
+	if rules[RuleMissingTags] != SeverityError {
+		t.Errorf("Expected missing tags to be an error, got %q", rules[RuleMissingTags])
+	}
+	for _, rule := range []string{RuleIDCase, RuleShortDescription, RuleFilenameMismatch} {
+		if _, reported := rules[rule]; reported {
+			t.Errorf("Expected no %s problem", rule)
+		}
+	}
+	if rules[RuleTokenBudget] != SeverityWarning {
+		t.Errorf("Expected token budget warning, got:\n%s", formatProblems(problems))
+	}
+}
+
+func TestLintDisable(t *testing.T) {
+	content := "# lint-disable missing-tags, modified-before-created\n" + messyPrompt
+	content = strings.Replace(content, `id: "Code_Review"`, `id: "Code_Review"  # lint-disable`, 1)
+	content = strings.Replace(content, `- name: "focus"`, `- name: "focus"  # lint-disable unused-argument`, 1)
+
+	problems := lintFile(t, New(DefaultConfig()), "review.yaml", content)
+	expected := "5: warning: description is 12 characters, shorter than 20 (short-description)\n" +
+		"12: warning: optional argument 'language' is not used in the prompt (unused-argument)\n" +
+		"22: warning: trailing whitespace (trailing-whitespace)"
+	if got := formatProblems(problems); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// A bare lint-disable comment disables every rule in the file
+	if problems := lintFile(t, New(DefaultConfig()), "review.yaml", "# lint-disable\n"+messyPrompt); len(problems) != 0 {
+		t.Errorf("Expected no problems, got:\n%s", formatProblems(problems))
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	names := RuleNames()
+	if len(names) != 8 || names[0] != RuleFilenameMismatch {
+		t.Errorf("Expected 8 sorted rules, got %v", names)
+	}
+	if !IsValidRule(RuleTokenBudget) || IsValidRule("unknown") {
+		t.Error("Unexpected IsValidRule result")
+	}
+	if !IsValidSeverity("warning") || IsValidSeverity("info") {
+		t.Error("Unexpected IsValidSeverity result")
+	}
+}
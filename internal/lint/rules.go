@@ -0,0 +1,167 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// Rule names, as used in configuration and lint-disable comments
+const (
+	RuleUnusedArgument        = "unused-argument"
+	RuleMissingTags           = "missing-tags"
+	RuleShortDescription      = "short-description"
+	RuleTokenBudget           = "token-budget"
+	RuleTrailingWhitespace    = "trailing-whitespace"
+	RuleIDCase                = "id-case"
+	RuleFilenameMismatch      = "filename-mismatch"
+	RuleModifiedBeforeCreated = "modified-before-created"
+)
+
+// charsPerToken is the rough number of characters in a token, used to
+// estimate the size of a prompt without a tokenizer
+const charsPerToken = 4
+
+// kebabCasePattern matches lower-case IDs with words separated by hyphens
+var kebabCasePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// finding is a problem found by a rule, before its severity is applied
+type finding struct {
+	line    int
+	message string
+}
+
+// rule checks a prompt for one kind of problem
+type rule struct {
+	severity Severity // default severity
+	check    func(config Config, p *prompt.Prompt, src *source) []finding
+}
+
+// rules holds every lint rule by name
+var rules = map[string]rule{
+	RuleUnusedArgument:        {SeverityWarning, checkUnusedArguments},
+	RuleMissingTags:           {SeverityWarning, checkMissingTags},
+	RuleShortDescription:      {SeverityWarning, checkShortDescription},
+	RuleTokenBudget:           {SeverityWarning, checkTokenBudget},
+	RuleTrailingWhitespace:    {SeverityWarning, checkTrailingWhitespace},
+	RuleIDCase:                {SeverityWarning, checkIDCase},
+	RuleFilenameMismatch:      {SeverityWarning, checkFilenameMismatch},
+	RuleModifiedBeforeCreated: {SeverityError, checkModifiedBeforeCreated},
+}
+
+// RuleNames returns the names of all rules in sorted order
+func RuleNames() []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsValidRule reports whether name is the name of a rule
+func IsValidRule(name string) bool {
+	_, exists := rules[name]
+	return exists
+}
+
+// checkUnusedArguments reports optional arguments that no template uses.
+// Unused required arguments are already rejected by validation.
+func checkUnusedArguments(config Config, p *prompt.Prompt, src *source) []finding {
+	used := make(map[string]bool)
+	for _, message := range p.Conversation() {
+		for _, text := range []string{message.Content, message.URI} {
+			template, err := prompt.ParseTemplate(text)
+			if err != nil {
+				continue
+			}
+			for _, variable := range template.Variables() {
+				used[variable] = true
+			}
+		}
+	}
+
+	var findings []finding
+	for _, arg := range p.Arguments {
+		if !arg.Required && !used[arg.Name] {
+			findings = append(findings, finding{src.argumentLine(arg.Name), fmt.Sprintf("optional argument '%s' is not used in the prompt", arg.Name)})
+		}
+	}
+	return findings
+}
+
+// checkMissingTags reports prompts without tags
+func checkMissingTags(config Config, p *prompt.Prompt, src *source) []finding {
+	if len(p.Metadata.Tags) > 0 {
+		return nil
+	}
+	return []finding{{src.line("metadata", "tags"), "prompt has no tags"}}
+}
+
+// checkShortDescription reports descriptions too short to tell prompts apart
+func checkShortDescription(config Config, p *prompt.Prompt, src *source) []finding {
+	length := utf8.RuneCountInString(strings.TrimSpace(p.Metadata.Description))
+	if length >= config.MinDescriptionLength {
+		return nil
+	}
+	return []finding{{src.line("metadata", "description"), fmt.Sprintf("description is %d characters, shorter than %d", length, config.MinDescriptionLength)}}
+}
+
+// checkTokenBudget reports prompt bodies estimated to exceed the token budget
+func checkTokenBudget(config Config, p *prompt.Prompt, src *source) []finding {
+	chars := 0
+	for _, message := range p.Conversation() {
+		chars += utf8.RuneCountInString(message.Content)
+	}
+
+	tokens := (chars + charsPerToken - 1) / charsPerToken
+	if tokens <= config.MaxTokens {
+		return nil
+	}
+	return []finding{{src.bodyLine(), fmt.Sprintf("prompt body is about %d tokens, over the budget of %d", tokens, config.MaxTokens)}}
+}
+
+// checkTrailingWhitespace reports lines of the file ending in whitespace
+func checkTrailingWhitespace(config Config, p *prompt.Prompt, src *source) []finding {
+	var findings []finding
+	for i, line := range src.lines {
+		line = strings.TrimSuffix(line, "\r")
+		if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+			findings = append(findings, finding{i + 1, "trailing whitespace"})
+		}
+	}
+	return findings
+}
+
+// checkIDCase reports IDs that are not kebab-case
+func checkIDCase(config Config, p *prompt.Prompt, src *source) []finding {
+	if kebabCasePattern.MatchString(p.Metadata.ID) {
+		return nil
+	}
+	return []finding{{src.line("metadata", "id"), fmt.Sprintf("id '%s' is not kebab-case", p.Metadata.ID)}}
+}
+
+// checkFilenameMismatch reports prompt files not named after their ID
+func checkFilenameMismatch(config Config, p *prompt.Prompt, src *source) []finding {
+	name := filepath.Base(p.FilePath)
+	if p.FilePath == "" || strings.TrimSuffix(name, filepath.Ext(name)) == p.Metadata.ID {
+		return nil
+	}
+	return []finding{{src.line("metadata", "id"), fmt.Sprintf("file name '%s' does not match id '%s'", name, p.Metadata.ID)}}
+}
+
+// checkModifiedBeforeCreated reports modified timestamps earlier than the
+// created timestamp
+func checkModifiedBeforeCreated(config Config, p *prompt.Prompt, src *source) []finding {
+	if !p.Metadata.Modified.Before(p.Metadata.Created) {
+		return nil
+	}
+	return []finding{{src.line("metadata", "modified"), fmt.Sprintf("modified %s is earlier than created %s",
+		p.Metadata.Modified.Format(time.RFC3339), p.Metadata.Created.Format(time.RFC3339))}}
+}
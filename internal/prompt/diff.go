@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"reflect"
+	"sort"
+)
+
+// LibraryDiff describes how the prompt set changed between two libraries
+type LibraryDiff struct {
+	Added    []string // IDs present only in the new library
+	Removed  []string // IDs present only in the old library
+	Modified []string // IDs present in both whose definition changed
+}
+
+// Empty reports whether the diff contains no changes
+func (d LibraryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffLibraries compares two libraries by prompt ID. A nil old library is
+// treated as empty, so every prompt in next is reported as added.
+func DiffLibraries(old, next *PromptLibrary) LibraryDiff {
+	var diff LibraryDiff
+
+	oldPrompts := map[string]*Prompt{}
+	if old != nil {
+		oldPrompts = old.Prompts
+	}
+
+	for id, p := range next.Prompts {
+		previous, exists := oldPrompts[id]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, id)
+		case promptChanged(previous, p):
+			diff.Modified = append(diff.Modified, id)
+		}
+	}
+
+	for id := range oldPrompts {
+		if _, exists := next.Prompts[id]; !exists {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+
+	return diff
+}
+
+// promptChanged compares two prompt definitions, ignoring usage statistics
+// which change at runtime without the definition changing
+func promptChanged(a, b *Prompt) bool {
+	left, right := *a, *b
+	left.UsageStats = UsageStats{}
+	right.UsageStats = UsageStats{}
+	return !reflect.DeepEqual(left, right)
+}
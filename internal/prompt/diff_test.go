@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLibraries(t *testing.T) {
+	newPrompt := func(id, body string) *Prompt {
+		return &Prompt{Metadata: Metadata{ID: id}, Prompt: body}
+	}
+
+	old := NewPromptLibrary()
+	old.AddPrompt(newPrompt("kept", "same"))
+	old.AddPrompt(newPrompt("changed", "before"))
+	old.AddPrompt(newPrompt("removed", "gone"))
+
+	next := NewPromptLibrary()
+	next.AddPrompt(newPrompt("kept", "same"))
+	next.AddPrompt(newPrompt("changed", "after"))
+	next.AddPrompt(newPrompt("added", "new"))
+
+	// Usage statistics alone must not count as a modification
+	next.Prompts["kept"].UsageStats.UsageCount = 42
+
+	diff := DiffLibraries(old, next)
+
+	if !reflect.DeepEqual(diff.Added, []string{"added"}) {
+		t.Errorf("Expected added [added], got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"removed"}) {
+		t.Errorf("Expected removed [removed], got %v", diff.Removed)
+	}
+	if !reflect.DeepEqual(diff.Modified, []string{"changed"}) {
+		t.Errorf("Expected modified [changed], got %v", diff.Modified)
+	}
+
+	if !DiffLibraries(next, next).Empty() {
+		t.Error("Expected diff of a library with itself to be empty")
+	}
+
+	if got := DiffLibraries(nil, next); len(got.Added) != 3 {
+		t.Errorf("Expected all prompts added from nil library, got %v", got.Added)
+	}
+}
@@ -0,0 +1,295 @@
+package prompt
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filter is a built-in template filter applied with {{name | filter args}}
+type filter struct {
+	// minArgs and maxArgs bound the number of arguments
+	minArgs, maxArgs int
+	// check validates the arguments when the template is parsed
+	check func(args []string) error
+	// apply transforms a value; value is nil when the variable has no value
+	apply func(value interface{}, args []string) (interface{}, error)
+	// handlesMissing is set for filters that apply to missing values
+	handlesMissing bool
+}
+
+// filterCall is a filter with its arguments as written in a template
+type filterCall struct {
+	name string
+	args []string
+}
+
+// filters is the registry of built-in filters
+var filters = map[string]filter{
+	"upper": {
+		apply: stringFilter(strings.ToUpper),
+	},
+	"lower": {
+		apply: stringFilter(strings.ToLower),
+	},
+	"trim": {
+		apply: stringFilter(strings.TrimSpace),
+	},
+	"indent": {
+		minArgs: 1, maxArgs: 1,
+		check: checkCount,
+		apply: applyIndent,
+	},
+	"truncate": {
+		minArgs: 1, maxArgs: 1,
+		check: checkCount,
+		apply: applyTruncate,
+	},
+	"fence": {
+		maxArgs: 1,
+		apply:   applyFence,
+	},
+	"join": {
+		minArgs: 1, maxArgs: 1,
+		apply: applyJoin,
+	},
+	"default": {
+		minArgs: 1, maxArgs: 1,
+		apply:          applyDefault,
+		handlesMissing: true,
+	},
+}
+
+// FilterNames returns the names of the built-in template filters, sorted
+func FilterNames() []string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parsePipeline parses the filters following the first | of a tag
+func parsePipeline(text string) ([]filterCall, error) {
+	segments, err := splitPipeline(text)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([]filterCall, 0, len(segments))
+	for _, fields := range segments {
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty filter")
+		}
+
+		call := filterCall{name: fields[0], args: fields[1:]}
+		f, exists := filters[call.name]
+		if !exists {
+			return nil, fmt.Errorf("unknown filter '%s', must be one of: %s", call.name, strings.Join(FilterNames(), ", "))
+		}
+		if len(call.args) < f.minArgs || len(call.args) > f.maxArgs {
+			return nil, fmt.Errorf("filter '%s' takes %s", call.name, describeArgCount(f))
+		}
+		if f.check != nil {
+			if err := f.check(call.args); err != nil {
+				return nil, fmt.Errorf("filter '%s': %w", call.name, err)
+			}
+		}
+
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+// splitPipeline splits "a 1 | b \"x y\"" into the fields of each filter.
+// Double-quoted arguments may contain spaces, | and Go escape sequences.
+func splitPipeline(text string) ([][]string, error) {
+	var (
+		segments [][]string
+		fields   []string
+		field    strings.Builder
+		inField  bool
+	)
+
+	endField := func() {
+		if inField {
+			fields = append(fields, field.String())
+			field.Reset()
+			inField = false
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"':
+			end := closingQuote(text, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in filter arguments")
+			}
+			value, err := strconv.Unquote(text[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s in filter arguments", text[i:end+1])
+			}
+			field.WriteString(value)
+			inField = true
+			i = end
+		case c == '|':
+			endField()
+			segments = append(segments, fields)
+			fields = nil
+		case unicode.IsSpace(rune(c)):
+			endField()
+		default:
+			field.WriteByte(c)
+			inField = true
+		}
+	}
+	endField()
+
+	return append(segments, fields), nil
+}
+
+// closingQuote returns the index of the quote closing the string at start
+func closingQuote(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// describeArgCount describes how many arguments a filter takes
+func describeArgCount(f filter) string {
+	switch {
+	case f.maxArgs == 0:
+		return "no arguments"
+	case f.minArgs == 0:
+		return fmt.Sprintf("at most %d argument(s)", f.maxArgs)
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d argument(s)", f.minArgs)
+	default:
+		return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+	}
+}
+
+// applyFilters runs value through a pipeline. exists reports whether the
+// variable has a value; filters other than default skip missing values.
+func applyFilters(value interface{}, exists bool, calls []filterCall) (interface{}, bool, error) {
+	for _, call := range calls {
+		f := filters[call.name]
+		if !exists && !f.handlesMissing {
+			continue
+		}
+
+		result, err := f.apply(value, call.args)
+		if err != nil {
+			return nil, false, fmt.Errorf("filter '%s': %w", call.name, err)
+		}
+		value, exists = result, true
+	}
+	return value, exists, nil
+}
+
+// stringFilter adapts a string function to a filter
+func stringFilter(fn func(string) string) func(interface{}, []string) (interface{}, error) {
+	return func(value interface{}, _ []string) (interface{}, error) {
+		return fn(formatValue(value)), nil
+	}
+}
+
+// checkCount requires a single non-negative integer argument
+func checkCount(args []string) error {
+	if n, err := strconv.Atoi(args[0]); err != nil || n < 0 {
+		return fmt.Errorf("expected a non-negative number, got '%s'", args[0])
+	}
+	return nil
+}
+
+// applyIndent indents every non-empty line by the given number of spaces
+func applyIndent(value interface{}, args []string) (interface{}, error) {
+	n, _ := strconv.Atoi(args[0])
+	padding := strings.Repeat(" ", n)
+
+	lines := strings.Split(formatValue(value), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = padding + line
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// applyTruncate keeps the first N characters, marking the cut with "..."
+func applyTruncate(value interface{}, args []string) (interface{}, error) {
+	n, _ := strconv.Atoi(args[0])
+
+	runes := []rune(formatValue(value))
+	if len(runes) <= n {
+		return string(runes), nil
+	}
+	return string(runes[:n]) + "...", nil
+}
+
+// applyFence wraps the value in a Markdown code fence with an optional
+// language, using a longer fence when the value already contains one
+func applyFence(value interface{}, args []string) (interface{}, error) {
+	text := strings.TrimRight(formatValue(value), "\n")
+
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	language := ""
+	if len(args) > 0 {
+		language = args[0]
+	}
+	return fence + language + "\n" + text + "\n" + fence, nil
+}
+
+// applyJoin joins the items of an array with a separator
+func applyJoin(value interface{}, args []string) (interface{}, error) {
+	items, err := loopItems(value)
+	if err != nil {
+		return formatValue(value), nil
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = formatValue(item)
+	}
+	return strings.Join(parts, args[0]), nil
+}
+
+// applyDefault replaces a missing or empty value
+func applyDefault(value interface{}, args []string) (interface{}, error) {
+	if isEmptyValue(value) {
+		return args[0], nil
+	}
+	return value, nil
+}
+
+// isEmptyValue reports whether a value is missing, an empty string or an
+// empty array. Unlike isTruthy, false and zero are values.
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,98 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		values   map[string]interface{}
+		expected string
+	}{
+		{"upper", "{{language | upper}}", map[string]interface{}{"language": "go"}, "GO"},
+		{"lower", "{{language|lower}}", map[string]interface{}{"language": "Go"}, "go"},
+		{"trim", "[{{text | trim}}]", map[string]interface{}{"text": "  x \n"}, "[x]"},
+		{"indent", "{{code | indent 4}}", map[string]interface{}{"code": "a\n\nb"}, "    a\n\n    b"},
+		{"truncate long", "{{text | truncate 3}}", map[string]interface{}{"text": "abcdef"}, "abc..."},
+		{"truncate short", "{{text | truncate 10}}", map[string]interface{}{"text": "abc"}, "abc"},
+		{"truncate runes", "{{text | truncate 2}}", map[string]interface{}{"text": "héllo"}, "hé..."},
+		{"fence", "{{code | fence \"go\"}}", map[string]interface{}{"code": "x := 1\n"}, "```go\nx := 1\n```"},
+		{"fence without language", "{{code | fence}}", map[string]interface{}{"code": "x"}, "```\nx\n```"},
+		{"fence around fence", "{{code | fence md}}", map[string]interface{}{"code": "```\nx\n```"}, "````md\n```\nx\n```\n````"},
+		{"join", "{{files | join \"; \"}}", map[string]interface{}{"files": []interface{}{"a", "b"}}, "a; b"},
+		{"default missing", "{{name | default \"unknown\"}}", map[string]interface{}{}, "unknown"},
+		{"default empty", "{{name | default \"unknown\"}}", map[string]interface{}{"name": ""}, "unknown"},
+		{"default keeps false", "{{flag | default \"yes\"}}", map[string]interface{}{"flag": false}, "false"},
+		{"default set", "{{name | default \"unknown\"}}", map[string]interface{}{"name": "Sam"}, "Sam"},
+		{"chain", "{{name | default \"anon\" | upper}}", map[string]interface{}{}, "ANON"},
+		{"missing without default kept", "{{name | upper}}", map[string]interface{}{}, "{{name | upper}}"},
+		{"quoted pipe", "{{files | join \" | \"}}", map[string]interface{}{"files": []string{"a", "b"}}, "a | b"},
+		{"loop item", "{{#each langs}}{{this | upper}} {{/each}}", map[string]interface{}{"langs": []interface{}{"go", "ts"}}, "GO TS "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseTemplate(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse template: %v", err)
+			}
+
+			result, err := template.Render(tt.values)
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"unknown filter", "x\n{{name | shout}}", "line 2: {{name | shout}}: unknown filter 'shout'"},
+		{"missing argument", "{{code | indent}}", "filter 'indent' takes 1 argument(s)"},
+		{"extra argument", "{{name | upper 2}}", "filter 'upper' takes no arguments"},
+		{"bad number", "{{text | truncate many}}", "expected a non-negative number, got 'many'"},
+		{"empty filter", "{{name | upper |}}", "empty filter"},
+		{"unterminated string", "{{name | default \"x}}", "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.content)
+			if err == nil {
+				t.Fatal("Expected parse error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidatePromptContentFilters(t *testing.T) {
+	arguments := []Argument{
+		{Name: "code", Description: "Code to review", Type: ArgumentTypeString, Required: true},
+	}
+
+	if err := validatePromptContent("{{code | fence \"go\" | indent 2}}", arguments); err != nil {
+		t.Errorf("Expected valid content, got: %v", err)
+	}
+
+	if err := validatePromptContent("{{code | highlight}}", arguments); err == nil || !strings.Contains(err.Error(), "unknown filter 'highlight'") {
+		t.Errorf("Expected unknown filter to be rejected, got: %v", err)
+	}
+
+	if err := validatePromptContent("{{code}} {{missing | upper}}", arguments); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected undefined filtered variable to be reported, got: %v", err)
+	}
+}
@@ -0,0 +1,368 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Prompt inheritance
+//
+// A prompt with "extends: <id>" starts from the resolved parent prompt:
+//
+//   - metadata fields the child leaves empty are taken from the parent
+//   - arguments are inherited; a child argument with the same name overrides
+//     the fields it sets, and new arguments are appended
+//   - the parent's template or messages are used unless the child sets its
+//     own prompt or messages
+//   - named blocks set by the parent are inherited and can be overridden
+//
+// Templates mark replaceable sections as named blocks:
+//
+//	{{#block checklist}}default content{{/block}}
+//
+// and prompts override them with a "blocks" map. Blocks are resolved when the
+// prompt is loaded, so the renderer never sees them.
+
+// blockTagPattern matches {{#block name}} and {{/block}} tags
+var blockTagPattern = regexp.MustCompile(`\{\{(?:#block\s+(\w+)|(/block))\}\}`)
+
+// inheritanceResolver resolves the extends chains of a set of prompts
+type inheritanceResolver struct {
+	prompts  map[string]*Prompt // prompts as read from disk, by ID
+	resolved map[string]*Prompt
+}
+
+// newInheritanceResolver creates a resolver for prompts keyed by ID
+func newInheritanceResolver(prompts map[string]*Prompt) *inheritanceResolver {
+	return &inheritanceResolver{
+		prompts:  prompts,
+		resolved: make(map[string]*Prompt),
+	}
+}
+
+// resolve returns the prompt with the given ID merged with its ancestors.
+// Named blocks are not yet resolved. chain holds the IDs of the descendants
+// being resolved and is used to detect cycles.
+func (r *inheritanceResolver) resolve(id string, chain []string) (*Prompt, error) {
+	if p, done := r.resolved[id]; done {
+		return p, nil
+	}
+
+	p := r.prompts[id]
+	if p.Extends == "" {
+		r.resolved[id] = p
+		return p, nil
+	}
+
+	chain = append(chain, id)
+	if containsString(chain, p.Extends) {
+		cycle := append(append([]string{}, chain...), p.Extends)
+		return nil, fmt.Errorf("extends cycle %s", strings.Join(cycle, " -> "))
+	}
+
+	if _, exists := r.prompts[p.Extends]; !exists {
+		return nil, fmt.Errorf("prompt '%s' extends unknown prompt '%s'", id, p.Extends)
+	}
+
+	parent, err := r.resolve(p.Extends, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := inherit(parent, p)
+	r.resolved[id] = merged
+	return merged, nil
+}
+
+// load resolves the prompt with the given ID, including its named blocks,
+// and validates the result
+func (r *inheritanceResolver) load(id string) (*Prompt, error) {
+	prompt, errs := r.check(id)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return prompt, nil
+}
+
+// check resolves and validates the prompt with the given ID like load, but
+// returns every validation problem rather than only the first
+func (r *inheritanceResolver) check(id string) (*Prompt, []error) {
+	resolved, err := r.resolve(id, nil)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	prompt := *resolved
+	if err := resolvePromptBlocks(&prompt); err != nil {
+		return nil, []error{fmt.Errorf("invalid blocks: %w", err)}
+	}
+
+	// Validate the loaded prompt
+	var errs []error
+	for _, err := range ValidatePromptAll(&prompt) {
+		errs = append(errs, fmt.Errorf("prompt validation failed: %w", err))
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &prompt, nil
+}
+
+// inherit merges child over parent into a new prompt
+func inherit(parent, child *Prompt) *Prompt {
+	merged := *child
+
+	merged.Metadata = inheritMetadata(parent.Metadata, child.Metadata)
+	merged.Arguments = inheritArguments(parent.Arguments, child.Arguments)
+
+	if strings.TrimSpace(child.Prompt) == "" && len(child.Messages) == 0 {
+		merged.Prompt = parent.Prompt
+		merged.Messages = parent.Messages
+	}
+
+	if len(parent.Blocks) > 0 {
+		merged.Blocks = make(map[string]string, len(parent.Blocks)+len(child.Blocks))
+		for name, content := range parent.Blocks {
+			merged.Blocks[name] = content
+		}
+		for name, content := range child.Blocks {
+			merged.Blocks[name] = content
+		}
+	}
+
+	return &merged
+}
+
+// inheritMetadata fills the fields child leaves empty from parent. The ID
+// is never inherited.
+func inheritMetadata(parent, child Metadata) Metadata {
+	merged := child
+
+	if merged.Name == "" {
+		merged.Name = parent.Name
+	}
+	if merged.Description == "" {
+		merged.Description = parent.Description
+	}
+	if merged.Author == "" {
+		merged.Author = parent.Author
+	}
+	if merged.Version == "" {
+		merged.Version = parent.Version
+	}
+	if merged.Created.IsZero() {
+		merged.Created = parent.Created
+	}
+	if merged.Modified.IsZero() {
+		merged.Modified = parent.Modified
+	}
+	if merged.Tags == nil {
+		merged.Tags = parent.Tags
+	}
+
+	return merged
+}
+
+// inheritArguments merges child arguments over parent arguments, keeping
+// the parent's order and appending new child arguments
+func inheritArguments(parent, child []Argument) []Argument {
+	merged := make([]Argument, len(parent))
+	copy(merged, parent)
+
+	for _, arg := range child {
+		overridden := false
+		for i := range merged {
+			if merged[i].Name == arg.Name {
+				merged[i] = overrideArgument(merged[i], arg)
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, arg)
+		}
+	}
+
+	return merged
+}
+
+// overrideArgument applies the fields set in override to base. Changing the
+// type drops the inherited values and item type, which belong to the old one.
+func overrideArgument(base, override Argument) Argument {
+	if override.Description != "" {
+		base.Description = override.Description
+	}
+	if override.Type != "" && override.Type != base.Type {
+		base.Type = override.Type
+		base.Values = nil
+		base.Items = ""
+	}
+	if override.Default != nil {
+		base.Default = override.Default
+	}
+	if override.Values != nil {
+		base.Values = override.Values
+	}
+	if override.Items != "" {
+		base.Items = override.Items
+	}
+	if override.Suggestions != nil {
+		base.Suggestions = override.Suggestions
+	}
+	if override.Completion != "" {
+		base.Completion = override.Completion
+	}
+	if override.requiredSet {
+		base.Required = override.Required
+		base.requiredSet = true
+	}
+	return base
+}
+
+// resolvePromptBlocks resolves the named blocks in the template or messages
+// of a prompt. Messages are copied rather than modified in place.
+func resolvePromptBlocks(prompt *Prompt) error {
+	if len(prompt.Messages) == 0 {
+		var err error
+		prompt.Prompt, err = resolveBlocks(prompt.Prompt, prompt.Blocks)
+		return err
+	}
+
+	// Every block override must be defined by at least one message
+	var all strings.Builder
+	for _, message := range prompt.Messages {
+		all.WriteString(message.Content)
+	}
+	if err := checkBlockOverrides(all.String(), prompt.Blocks); err != nil {
+		return err
+	}
+
+	messages := make([]Message, len(prompt.Messages))
+	for i, message := range prompt.Messages {
+		messages[i] = message
+		content, err := expandBlocks(message.Content, prompt.Blocks, nil)
+		if err != nil {
+			return fmt.Errorf("message %d: %w", i, err)
+		}
+		messages[i].Content = content
+	}
+	prompt.Messages = messages
+	return nil
+}
+
+// resolveBlocks replaces the named blocks in content with their overrides
+// from blocks, or with their default content. Overrides for blocks that the
+// template does not define are rejected.
+func resolveBlocks(content string, blocks map[string]string) (string, error) {
+	if err := checkBlockOverrides(content, blocks); err != nil {
+		return "", err
+	}
+	return expandBlocks(content, blocks, nil)
+}
+
+// checkBlockOverrides rejects overrides for blocks that neither content nor
+// another override defines
+func checkBlockOverrides(content string, blocks map[string]string) error {
+	defined := make(map[string]bool)
+	for _, text := range append([]string{content}, blockValues(blocks)...) {
+		for _, match := range blockTagPattern.FindAllStringSubmatch(text, -1) {
+			if match[1] != "" {
+				defined[match[1]] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(blocks))
+	for name := range blocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !defined[name] {
+			return fmt.Errorf("block '%s' is not defined by the template", name)
+		}
+	}
+	return nil
+}
+
+// blockValues returns the override texts of blocks
+func blockValues(blocks map[string]string) []string {
+	values := make([]string, 0, len(blocks))
+	for _, text := range blocks {
+		values = append(values, text)
+	}
+	return values
+}
+
+// expandBlocks expands the blocks in content; stack holds the names of the
+// blocks being expanded and is used to detect blocks that contain themselves
+func expandBlocks(content string, blocks map[string]string, stack []string) (string, error) {
+	tags := blockTagPattern.FindAllStringSubmatchIndex(content, -1)
+	if len(tags) == 0 {
+		return content, nil
+	}
+
+	var builder strings.Builder
+	position := 0
+
+	for i := 0; i < len(tags); i++ {
+		open := tags[i]
+		if open[2] < 0 {
+			return "", fmt.Errorf("line %d: unexpected {{/block}}", lineAt(content, open[0]))
+		}
+		name := content[open[2]:open[3]]
+
+		// Find the matching close tag
+		closing, depth := -1, 0
+		for j := i + 1; j < len(tags) && closing < 0; j++ {
+			switch {
+			case tags[j][2] >= 0:
+				depth++
+			case depth > 0:
+				depth--
+			default:
+				closing = j
+			}
+		}
+		if closing < 0 {
+			return "", fmt.Errorf("line %d: {{#block %s}} is never closed", lineAt(content, open[0]), name)
+		}
+		if containsString(stack, name) {
+			return "", fmt.Errorf("block '%s' contains itself", name)
+		}
+
+		openStart, openEnd := standaloneSpan(content, position, open[0], open[1])
+		closeStart, closeEnd := standaloneSpan(content, openEnd, tags[closing][0], tags[closing][1])
+		standalone := openStart != open[0] || openEnd != open[1]
+
+		text, overridden := blocks[name]
+		if !overridden {
+			text = content[openEnd:closeStart]
+		} else if standalone && text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		} else if !standalone {
+			text = strings.TrimSuffix(text, "\n")
+		}
+
+		expanded, err := expandBlocks(text, blocks, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteString(content[position:openStart])
+		builder.WriteString(expanded)
+		position = closeEnd
+		i = closing
+	}
+
+	builder.WriteString(content[position:])
+	return builder.String(), nil
+}
+
+// lineAt returns the line number of the byte offset in content
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,286 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const basePrompt = `metadata:
+  id: "code-review"
+  name: "Code Review"
+  description: "Reviews code"
+  author: "team"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+  tags: ["review"]
+
+arguments:
+  - name: "code"
+    description: "Code to review"
+    type: "string"
+    required: true
+  - name: "focus"
+    description: "What to focus on"
+    type: "string"
+    default: "quality"
+
+prompt: |
+  Review this code for {{focus}}:
+  {{code}}
+  {{#block checklist}}
+  Please provide:
+  1. Issues
+  {{/block}}
+  {{#block closing}}Be constructive.{{/block}}
+`
+
+const goPrompt = `metadata:
+  id: "go-review"
+  name: "Go Review"
+  tags: ["review", "go"]
+
+extends: "code-review"
+
+arguments:
+  - name: "focus"
+    default: "idiomatic Go"
+  - name: "go_version"
+    description: "Go version"
+    type: "string"
+
+blocks:
+  checklist: |
+    Please check:
+    1. Error handling
+    2. Goroutine leaks ({{go_version}})
+`
+
+const strictGoPrompt = `metadata:
+  id: "strict-go-review"
+  name: "Strict Go Review"
+
+extends: "go-review"
+
+blocks:
+  closing: "Be strict."
+`
+
+func TestLoadAllPromptsInheritance(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "review/base.yaml", basePrompt)
+	writeFile(t, dir, "review/go.yaml", goPrompt)
+	strictPath := writeFile(t, dir, "review/strict-go.yaml", strictGoPrompt)
+
+	library, err := NewLoader(dir).LoadAllPrompts()
+	if err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	base, _ := library.GetPrompt("code-review")
+	expectedBase := "Review this code for {{focus}}:\n{{code}}\nPlease provide:\n1. Issues\nBe constructive.\n"
+	if base.Prompt != expectedBase {
+		t.Errorf("Expected base prompt %q, got %q", expectedBase, base.Prompt)
+	}
+
+	child, _ := library.GetPrompt("go-review")
+	expectedChild := "Review this code for {{focus}}:\n{{code}}\nPlease check:\n1. Error handling\n2. Goroutine leaks ({{go_version}})\nBe constructive.\n"
+	if child.Prompt != expectedChild {
+		t.Errorf("Expected child prompt %q, got %q", expectedChild, child.Prompt)
+	}
+	if child.Metadata.Author != "team" || child.Metadata.Version != "1.0.0" || child.Metadata.Description != "Reviews code" {
+		t.Errorf("Expected metadata defaults from parent, got %+v", child.Metadata)
+	}
+	if !reflect.DeepEqual(child.Metadata.Tags, []string{"review", "go"}) {
+		t.Errorf("Expected child tags, got %v", child.Metadata.Tags)
+	}
+
+	if len(child.Arguments) != 3 {
+		t.Fatalf("Expected 3 arguments, got %d", len(child.Arguments))
+	}
+	focus := child.Arguments[1]
+	if focus.Name != "focus" || focus.Default != "idiomatic Go" || focus.Description != "What to focus on" || focus.Type != ArgumentTypeString {
+		t.Errorf("Expected focus default overridden and other fields inherited, got %+v", focus)
+	}
+	if child.Arguments[2].Name != "go_version" {
+		t.Errorf("Expected new argument appended, got %s", child.Arguments[2].Name)
+	}
+
+	grandchild, _ := library.GetPrompt("strict-go-review")
+	if !strings.Contains(grandchild.Prompt, "Goroutine leaks") || !strings.HasSuffix(grandchild.Prompt, "Be strict.\n") {
+		t.Errorf("Expected inherited and overridden blocks, got %q", grandchild.Prompt)
+	}
+	if base.Prompt != expectedBase {
+		t.Error("Expected resolving children to leave the parent unchanged")
+	}
+
+	single, err := NewLoader(dir).LoadPrompt(strictPath)
+	if err != nil {
+		t.Fatalf("Failed to load prompt: %v", err)
+	}
+	if single.Prompt != grandchild.Prompt {
+		t.Errorf("Expected LoadPrompt to resolve inheritance, got %q", single.Prompt)
+	}
+}
+
+func TestInheritanceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+	}{
+		{
+			name:    "missing parent",
+			files:   map[string]string{"go.yaml": goPrompt},
+			message: "prompt 'go-review' extends unknown prompt 'code-review'",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"base.yaml":   strings.Replace(basePrompt, "tags: [\"review\"]\n", "tags: [\"review\"]\n\nextends: \"strict-go-review\"\n", 1),
+				"go.yaml":     goPrompt,
+				"strict.yaml": strictGoPrompt,
+			},
+			message: "extends cycle code-review -> strict-go-review -> go-review -> code-review",
+		},
+		{
+			name: "unknown block",
+			files: map[string]string{
+				"base.yaml": basePrompt,
+				"go.yaml":   strings.Replace(goPrompt, "checklist:", "summary:", 1),
+			},
+			message: "block 'summary' is not defined by the template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+
+			_, err := NewLoader(dir).LoadAllPrompts()
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestOverrideArgument(t *testing.T) {
+	base := Argument{
+		Name:        "path",
+		Description: "File to review",
+		Type:        ArgumentTypeString,
+		Suggestions: []string{"main.go"},
+		Completion:  CompletionPath,
+	}
+
+	tests := []struct {
+		name     string
+		override Argument
+		base     Argument // defaults to the path argument above
+		expected Argument
+	}{
+		{
+			name:     "inherits completion fields",
+			override: Argument{Name: "path", Description: "Go file"},
+			expected: Argument{Name: "path", Description: "Go file", Type: ArgumentTypeString, Suggestions: []string{"main.go"}, Completion: CompletionPath},
+		},
+		{
+			name:     "overrides completion fields",
+			override: Argument{Name: "path", Suggestions: []string{"cmd/server/main.go"}, Completion: "other"},
+			expected: Argument{Name: "path", Description: "File to review", Type: ArgumentTypeString, Suggestions: []string{"cmd/server/main.go"}, Completion: "other"},
+		},
+		{
+			name:     "keeps required when unset",
+			override: Argument{Name: "path", Required: false},
+			base:     Argument{Name: "path", Required: true, requiredSet: true},
+			expected: Argument{Name: "path", Required: true, requiredSet: true},
+		},
+		{
+			name:     "makes required argument optional",
+			override: Argument{Name: "path", Required: false, requiredSet: true},
+			base:     Argument{Name: "path", Required: true, requiredSet: true},
+			expected: Argument{Name: "path", Required: false, requiredSet: true},
+		},
+		{
+			name:     "type change drops values and items",
+			override: Argument{Name: "path", Type: ArgumentTypeString},
+			base:     Argument{Name: "path", Type: ArgumentTypeArray, Items: ArgumentTypeEnum, Values: []string{"a", "b"}},
+			expected: Argument{Name: "path", Type: ArgumentTypeString},
+		},
+		{
+			name:     "same type keeps values",
+			override: Argument{Name: "path", Type: ArgumentTypeEnum},
+			base:     Argument{Name: "path", Type: ArgumentTypeEnum, Values: []string{"a", "b"}},
+			expected: Argument{Name: "path", Type: ArgumentTypeEnum, Values: []string{"a", "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := base
+			if tt.base.Name != "" {
+				parent = tt.base
+			}
+			if got := overrideArgument(parent, tt.override); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestChildMakesArgumentOptional(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", basePrompt)
+	writeFile(t, dir, "go.yaml", strings.Replace(goPrompt, "arguments:\n", "arguments:\n  - name: \"code\"\n    required: false\n", 1))
+
+	library, err := NewLoader(dir).LoadAllPrompts()
+	if err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	base, _ := library.GetPrompt("code-review")
+	child, _ := library.GetPrompt("go-review")
+	if !base.Arguments[0].Required || child.Arguments[0].Required {
+		t.Errorf("Expected code required in the parent only, got %+v and %+v", base.Arguments[0], child.Arguments[0])
+	}
+}
+
+func TestResolveBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		blocks   map[string]string
+		expected string
+		message  string
+	}{
+		{"defaults", "A {{#block b}}default{{/block}} C", nil, "A default C", ""},
+		{"inline override", "A {{#block b}}default{{/block}} C", map[string]string{"b": "new\n"}, "A new C", ""},
+		{"nested default", "{{#block outer}}[{{#block inner}}x{{/block}}]{{/block}}", map[string]string{"inner": "y"}, "[y]", ""},
+		{"unclosed", "{{#block b}}x", nil, "", "{{#block b}} is never closed"},
+		{"stray close", "x{{/block}}", nil, "", "unexpected {{/block}}"},
+		{"self reference", "{{#block b}}x{{/block}}", map[string]string{"b": "{{#block b}}y{{/block}}"}, "", "block 'b' contains itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveBlocks(tt.content, tt.blocks)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Errorf("Expected error containing %q, got %v", tt.message, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
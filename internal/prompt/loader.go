@@ -4,30 +4,149 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// LoadMode controls how LoadAllPrompts handles prompt files that fail to load
+type LoadMode string
+
+const (
+	// LoadStrict fails the whole load on the first invalid prompt file
+	LoadStrict LoadMode = "strict"
+	// LoadLenient skips invalid prompt files and reports them as diagnostics
+	LoadLenient LoadMode = "lenient"
+)
+
+// IsValidLoadMode reports whether mode names a supported load mode
+func IsValidLoadMode(mode string) bool {
+	switch LoadMode(mode) {
+	case LoadStrict, LoadLenient:
+		return true
+	default:
+		return false
+	}
+}
+
+// Diagnostic describes a prompt file that was skipped in lenient mode
+type Diagnostic struct {
+	FilePath string `json:"file"`
+	PromptID string `json:"prompt_id,omitempty"` // empty if the file could not be parsed
+	Line     int    `json:"line,omitempty"`      // line in the file, if known
+	Message  string `json:"message"`
+}
+
+// String formats the diagnostic as "file:line: message"
+func (d Diagnostic) String() string {
+	if d.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", d.FilePath, d.Line, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.FilePath, d.Message)
+}
+
+// yamlLinePattern finds the line number in YAML parser errors
+var yamlLinePattern = regexp.MustCompile(`yaml: (?:unmarshal errors:\s+)?line (\d+)`)
+
+// yamlErrorLine returns the file line a YAML parse error refers to, or zero
+// for other errors
+func yamlErrorLine(err error) int {
+	if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return line
+	}
+	return 0
+}
+
 // Loader handles loading prompts from the filesystem
 type Loader struct {
 	promptsDir string
+	mode       LoadMode
 }
 
-// NewLoader creates a new prompt loader
+// NewLoader creates a new prompt loader in strict mode
 func NewLoader(promptsDir string) *Loader {
 	return &Loader{
 		promptsDir: promptsDir,
+		mode:       LoadStrict,
 	}
 }
 
-// LoadAllPrompts loads all prompts from the prompts directory
+// SetMode sets how invalid prompt files are handled
+func (l *Loader) SetMode(mode LoadMode) {
+	l.mode = mode
+}
+
+// LoadAllPrompts loads all prompts from the prompts directory. In lenient
+// mode prompt files that fail to load are skipped and described in the
+// library's diagnostics; partials must still load.
 func (l *Loader) LoadAllPrompts() (*PromptLibrary, error) {
+	partials, err := l.LoadPartials()
+	if err != nil {
+		return nil, err
+	}
+
+	prompts, diagnostics, err := l.readAllPrompts(partials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompts from directory %s: %w", l.promptsDir, err)
+	}
+
+	// Resolve inheritance once every prompt is known
+	ids := make([]string, 0, len(prompts))
+	for id := range prompts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
 	library := NewPromptLibrary()
+	resolver := newInheritanceResolver(prompts)
+	for _, id := range ids {
+		prompt, errs := resolver.check(id)
+		if len(errs) > 0 {
+			if l.mode == LoadLenient {
+				for _, err := range errs {
+					diagnostics = append(diagnostics, Diagnostic{FilePath: prompts[id].FilePath, PromptID: id, Message: err.Error()})
+				}
+				continue
+			}
+			err := fmt.Errorf("failed to load prompt from %s: %w", prompts[id].FilePath, errs[0])
+			return nil, fmt.Errorf("failed to load prompts from directory %s: %w", l.promptsDir, err)
+		}
+		library.AddPrompt(prompt)
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool { return diagnostics[i].FilePath < diagnostics[j].FilePath })
+	library.Diagnostics = diagnostics
+
+	return library, nil
+}
+
+// readAllPrompts reads every prompt file in the prompts directory without
+// resolving inheritance, keyed by prompt ID. In lenient mode files that
+// cannot be read are returned as diagnostics instead of failing the walk.
+func (l *Loader) readAllPrompts(partials Partials) (map[string]*Prompt, []Diagnostic, error) {
+	prompts := make(map[string]*Prompt)
+	partialsDir := filepath.Join(l.promptsDir, PartialsDir)
+	var diagnostics []Diagnostic
 
 	err := filepath.Walk(l.promptsDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			if l.mode != LoadLenient || path == l.promptsDir {
+				return err
+			}
+			diagnostics = append(diagnostics, Diagnostic{FilePath: path, Message: err.Error()})
+			if info != nil && info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Partials are included by prompts, not prompts themselves
+		if info.IsDir() && path == partialsDir {
+			return filepath.SkipDir
 		}
 
 		// Skip directories and non-YAML files
@@ -35,8 +154,12 @@ func (l *Loader) LoadAllPrompts() (*PromptLibrary, error) {
 			return nil
 		}
 
-		prompt, err := l.LoadPrompt(path)
+		prompt, err := l.readPrompt(path, partials)
 		if err != nil {
+			if l.mode == LoadLenient {
+				diagnostics = append(diagnostics, Diagnostic{FilePath: path, Line: yamlErrorLine(err), Message: err.Error()})
+				return nil
+			}
 			return fmt.Errorf("failed to load prompt from %s: %w", path, err)
 		}
 
@@ -44,23 +167,68 @@ func (l *Loader) LoadAllPrompts() (*PromptLibrary, error) {
 		prompt.FilePath = path
 
 		// Check for duplicate IDs
-		if _, exists := library.GetPrompt(prompt.Metadata.ID); exists {
+		if existing, exists := prompts[prompt.Metadata.ID]; exists {
+			if l.mode == LoadLenient {
+				diagnostics = append(diagnostics, Diagnostic{
+					FilePath: path,
+					PromptID: prompt.Metadata.ID,
+					Message:  fmt.Sprintf("duplicate prompt ID '%s', already defined in file %s", prompt.Metadata.ID, existing.FilePath),
+				})
+				return nil
+			}
 			return fmt.Errorf("duplicate prompt ID '%s' found in file %s", prompt.Metadata.ID, path)
 		}
 
-		library.AddPrompt(prompt)
+		prompts[prompt.Metadata.ID] = prompt
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to load prompts from directory %s: %w", l.promptsDir, err)
+		return nil, nil, err
 	}
 
-	return library, nil
+	return prompts, diagnostics, nil
 }
 
-// LoadPrompt loads a single prompt from a file
+// LoadPrompt loads a single prompt from a file. A prompt that extends
+// another prompt is resolved against the prompts directory.
 func (l *Loader) LoadPrompt(filePath string) (*Prompt, error) {
+	partials, err := l.LoadPartials()
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := l.readPrompt(filePath, partials)
+	if err != nil {
+		return nil, err
+	}
+
+	prompts, err := l.withAncestors(prompt, partials)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInheritanceResolver(prompts).load(prompt.Metadata.ID)
+}
+
+// withAncestors returns the prompts needed to resolve prompt's inheritance,
+// keyed by ID, with prompt itself taking precedence over the file on disk
+func (l *Loader) withAncestors(prompt *Prompt, partials Partials) (map[string]*Prompt, error) {
+	prompts := make(map[string]*Prompt)
+	if prompt.Extends != "" {
+		var err error
+		if prompts, _, err = l.readAllPrompts(partials); err != nil {
+			return nil, fmt.Errorf("failed to load parent prompts: %w", err)
+		}
+	}
+
+	prompts[prompt.Metadata.ID] = prompt
+	return prompts, nil
+}
+
+// readPrompt reads a single prompt from a file and expands its includes.
+// The prompt is validated once its inheritance has been resolved.
+func (l *Loader) readPrompt(filePath string, partials Partials) (*Prompt, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -71,19 +239,33 @@ func (l *Loader) LoadPrompt(filePath string) (*Prompt, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
-	// Validate the loaded prompt
-	if err := ValidatePrompt(&prompt); err != nil {
-		return nil, fmt.Errorf("prompt validation failed: %w", err)
+	// Resolve includes before validating the complete template
+	if err := partials.expandPrompt(&prompt, filePath); err != nil {
+		return nil, err
 	}
 
 	return &prompt, nil
 }
 
-// SavePrompt saves a prompt to a file
+// SavePrompt saves a prompt to a file. Includes, inherited fields and block
+// overrides are written as given.
 func (l *Loader) SavePrompt(prompt *Prompt, filePath string) error {
-	// Validate before saving
-	if err := ValidatePrompt(prompt); err != nil {
-		return fmt.Errorf("prompt validation failed: %w", err)
+	partials, err := l.LoadPartials()
+	if err != nil {
+		return err
+	}
+
+	// Validate before saving, as the prompt will be loaded
+	expanded := *prompt
+	if err := partials.expandPrompt(&expanded, filePath); err != nil {
+		return err
+	}
+	prompts, err := l.withAncestors(&expanded, partials)
+	if err != nil {
+		return err
+	}
+	if _, err := newInheritanceResolver(prompts).load(prompt.Metadata.ID); err != nil {
+		return err
 	}
 
 	// Ensure directory exists
@@ -123,6 +305,14 @@ func (l *Loader) GetCategoryFromPath(filePath string) string {
 	return "uncategorized"
 }
 
+// categoryPattern matches valid category directory names
+var categoryPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// IsValidCategory reports whether name can be used as a category directory
+func IsValidCategory(name string) bool {
+	return categoryPattern.MatchString(name) && name != PartialsDir
+}
+
 // GeneratePromptURI generates a URI for a prompt based on its file path
 func (l *Loader) GeneratePromptURI(filePath string) string {
 	category := l.GetCategoryFromPath(filePath)
@@ -3,6 +3,7 @@ package prompt
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -97,4 +98,66 @@ func TestLoadAllPrompts(t *testing.T) {
 			t.Errorf("Prompt ID mismatch: expected %s, got %s", id, prompt.Metadata.ID)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestLenientLoading(t *testing.T) {
+	dir := t.TempDir()
+	valid := func(id, extra string) string {
+		return `metadata:
+  id: "` + id + `"
+  name: "Prompt"
+  description: "A test prompt"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+` + extra + `
+prompt: "Hello"
+`
+	}
+	writeFile(t, dir, "a/good.yaml", valid("good", ""))
+	writeFile(t, dir, "a/broken.yaml", "metadata: [unclosed\n")
+	writeFile(t, dir, "b/duplicate.yaml", valid("good", ""))
+	writeFile(t, dir, "b/invalid.yaml", valid("invalid", "arguments:\n  - name: \"x\"\n    description: \"X\"\n    type: \"color\"\n  - name: \"y\"\n    type: \"string\"\n"))
+	writeFile(t, dir, "b/orphan.yaml", valid("orphan", "extends: \"missing\"\n"))
+
+	if _, err := NewLoader(dir).LoadAllPrompts(); err == nil {
+		t.Fatal("Expected strict loading to fail")
+	}
+
+	loader := NewLoader(dir)
+	loader.SetMode(LoadLenient)
+	library, err := loader.LoadAllPrompts()
+	if err != nil {
+		t.Fatalf("Expected lenient loading to succeed, got: %v", err)
+	}
+
+	if library.Len() != 1 {
+		t.Errorf("Expected only the valid prompt to load, got %d prompts", library.Len())
+	}
+	if p, exists := library.GetPrompt("good"); !exists || p.FilePath != filepath.Join(dir, "a", "good.yaml") {
+		t.Errorf("Expected the first 'good' prompt to be kept, got %+v", p)
+	}
+
+	expected := []struct {
+		file, id, message string
+	}{
+		{"a/broken.yaml", "", "failed to parse YAML"},
+		{"b/duplicate.yaml", "good", "duplicate prompt ID 'good', already defined in file " + filepath.Join(dir, "a", "good.yaml")},
+		{"b/invalid.yaml", "invalid", "argument 0 (x): invalid type 'color'"},
+		{"b/invalid.yaml", "invalid", "argument 1 (y): description is required"},
+		{"b/orphan.yaml", "orphan", "prompt 'orphan' extends unknown prompt 'missing'"},
+	}
+	if len(library.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %v", len(expected), library.Diagnostics)
+	}
+	if line := library.Diagnostics[0].Line; line != 1 {
+		t.Errorf("Expected the YAML error on line 1, got %d", line)
+	}
+	for i, want := range expected {
+		got := library.Diagnostics[i]
+		if got.FilePath != filepath.Join(dir, want.file) || got.PromptID != want.id || !strings.Contains(got.Message, want.message) {
+			t.Errorf("Diagnostic %d: expected %s (%s) containing %q, got %+v", i, want.file, want.id, want.message, got)
+		}
+	}
+}
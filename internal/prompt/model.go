@@ -2,13 +2,18 @@ package prompt
 
 import (
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Prompt represents a prompt template with metadata
 type Prompt struct {
 	Metadata    Metadata     `yaml:"metadata"`
+	Extends     string       `yaml:"extends,omitempty"` // ID of the prompt this one inherits from
 	Arguments   []Argument   `yaml:"arguments,omitempty"`
-	Prompt      string       `yaml:"prompt"`
+	Prompt      string       `yaml:"prompt,omitempty"`
+	Messages    []Message    `yaml:"messages,omitempty"` // alternative to Prompt for multi-message prompts
+	Blocks      map[string]string `yaml:"blocks,omitempty"` // overrides for named template blocks
 	UsageStats  UsageStats   `yaml:"usage_stats"`
 	FilePath    string       `yaml:"-"` // Internal field, not serialized
 }
@@ -32,6 +37,43 @@ type Argument struct {
 	Type        ArgumentType `yaml:"type"`
 	Required    bool        `yaml:"required"`
 	Default     interface{} `yaml:"default,omitempty"`
+	Values      []string     `yaml:"values,omitempty"` // allowed values for enum arguments and enum array items
+	Items       ArgumentType `yaml:"items,omitempty"`  // item type for array arguments
+	Suggestions []string     `yaml:"suggestions,omitempty"` // values offered by completion, not enforced
+	Completion  CompletionSource `yaml:"completion,omitempty"` // extra completion source
+
+	requiredSet bool // whether the definition sets required, see overrideArgument
+}
+
+// UnmarshalYAML decodes an argument and records whether it sets required
+func (a *Argument) UnmarshalYAML(value *yaml.Node) error {
+	type plain Argument
+	if err := value.Decode((*plain)(a)); err != nil {
+		return err
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if value.Content[i].Value == "required" {
+			a.requiredSet = true
+		}
+	}
+	return nil
+}
+
+// CompletionSource names an additional source of argument completions
+type CompletionSource string
+
+const (
+	// CompletionPath completes file paths below the server's embed root
+	CompletionPath CompletionSource = "path"
+)
+
+// ValueType returns the type of a single value of the argument: the item
+// type for arrays and the argument type otherwise
+func (a Argument) ValueType() ArgumentType {
+	if a.Type == ArgumentTypeArray {
+		return a.Items
+	}
+	return a.Type
 }
 
 // ArgumentType defines the types of arguments supported
@@ -41,17 +83,79 @@ const (
 	ArgumentTypeString  ArgumentType = "string"
 	ArgumentTypeNumber  ArgumentType = "number"
 	ArgumentTypeBoolean ArgumentType = "boolean"
+	ArgumentTypeEnum    ArgumentType = "enum"
+	ArgumentTypeArray   ArgumentType = "array"
 )
 
-// UsageStats tracks usage statistics for a prompt
+// AllowedValues returns the values an enum argument, or the items of an enum
+// array argument, may take. It returns nil for other arguments.
+func (a Argument) AllowedValues() []string {
+	if a.Type == ArgumentTypeEnum || (a.Type == ArgumentTypeArray && a.Items == ArgumentTypeEnum) {
+		return a.Values
+	}
+	return nil
+}
+
+// Message is one templated message of a multi-message prompt. Text
+// messages carry Content; resource messages embed the resource at URI.
+type Message struct {
+	Role    MessageRole `yaml:"role"`
+	Type    MessageType `yaml:"type,omitempty"`
+	Content string      `yaml:"content,omitempty"`
+	URI     string      `yaml:"uri,omitempty"`
+}
+
+// MessageType is the kind of content a message carries
+type MessageType string
+
+const (
+	MessageTypeText     MessageType = "text"
+	MessageTypeResource MessageType = "resource"
+)
+
+// EmbedSchemes are the URI schemes resource messages may embed
+var EmbedSchemes = []string{"file://", "prompt://"}
+
+// IsResource reports whether the message embeds a resource
+func (m Message) IsResource() bool {
+	return m.Type == MessageTypeResource
+}
+
+// MessageRole is the author of a prompt message
+type MessageRole string
+
+const (
+	MessageRoleSystem    MessageRole = "system"
+	MessageRoleUser      MessageRole = "user"
+	MessageRoleAssistant MessageRole = "assistant"
+)
+
+// Conversation returns the messages of the prompt in order. A prompt
+// defined with a single prompt template is one user message.
+func (p *Prompt) Conversation() []Message {
+	if len(p.Messages) > 0 {
+		return p.Messages
+	}
+	return []Message{{Role: MessageRoleUser, Content: p.Prompt}}
+}
+
+// UsageStats holds the usage_stats block of a prompt file. The server records
+// runtime usage in a separate store and does not update this block.
 type UsageStats struct {
 	UsageCount int       `yaml:"usage_count"`
 	LastUsed   time.Time `yaml:"last_used"`
 }
 
-// PromptLibrary holds all loaded prompts
+// PromptLibrary holds all loaded prompts.
+//
+// A library is an immutable snapshot once it has been published: the loader
+// builds a fresh library on every reload and readers hold on to the snapshot
+// they started with, so neither the library nor its prompts may be modified
+// after construction. Generation increases with every published snapshot.
 type PromptLibrary struct {
-	Prompts map[string]*Prompt // keyed by prompt ID
+	Prompts     map[string]*Prompt // keyed by prompt ID
+	Diagnostics []Diagnostic       // prompt files skipped by a lenient load
+	Generation  uint64
 }
 
 // NewPromptLibrary creates a new prompt library
@@ -61,7 +165,8 @@ func NewPromptLibrary() *PromptLibrary {
 	}
 }
 
-// AddPrompt adds a prompt to the library
+// AddPrompt adds a prompt to the library. It must only be called while the
+// library is being built, before it is shared with readers.
 func (pl *PromptLibrary) AddPrompt(prompt *Prompt) {
 	pl.Prompts[prompt.Metadata.ID] = prompt
 }
@@ -72,6 +177,11 @@ func (pl *PromptLibrary) GetPrompt(id string) (*Prompt, bool) {
 	return prompt, exists
 }
 
+// Len returns the number of prompts in the library
+func (pl *PromptLibrary) Len() int {
+	return len(pl.Prompts)
+}
+
 // ListPrompts returns all prompts
 func (pl *PromptLibrary) ListPrompts() []*Prompt {
 	prompts := make([]*Prompt, 0, len(pl.Prompts))
@@ -0,0 +1,177 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PartialsDir is the directory inside the prompts directory that holds
+// partials. It is not searched for prompts.
+const PartialsDir = "partials"
+
+// PartialExt is the file extension of partials
+const PartialExt = ".md"
+
+// includePattern matches {{> name}} includes. Names may contain slashes for
+// partials in subdirectories of the partials directory.
+var includePattern = regexp.MustCompile(`\{\{>\s*([\w./-]+)\s*\}\}`)
+
+// Partial is a template fragment shared between prompts
+type Partial struct {
+	Name     string
+	Content  string
+	FilePath string
+}
+
+// Partials holds the partials of a library by name
+type Partials map[string]*Partial
+
+// LoadPartials loads all partials below the partials directory. A missing
+// directory means there are no partials.
+func (l *Loader) LoadPartials() (Partials, error) {
+	partials := make(Partials)
+	root := filepath.Join(l.promptsDir, PartialsDir)
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == root && errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), PartialExt) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+
+		if existing, exists := partials[name]; exists {
+			return fmt.Errorf("duplicate partial '%s' found in files %s and %s", name, existing.FilePath, path)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read partial %s: %w", path, err)
+		}
+
+		partials[name] = &Partial{Name: name, Content: string(data), FilePath: path}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load partials from directory %s: %w", root, err)
+	}
+
+	return partials, nil
+}
+
+// Expand replaces the {{> name}} includes in content, which was read from
+// source, with the named partials. Includes inside partials are expanded
+// too. An include alone on its line is indented like the include tag.
+func (p Partials) Expand(content, source string) (string, error) {
+	return p.expand(content, source, nil)
+}
+
+// expandPrompt expands the includes in the template, messages and block
+// overrides of a prompt read from source. Messages and blocks are copied
+// rather than modified in place.
+func (p Partials) expandPrompt(prompt *Prompt, source string) error {
+	var err error
+	if prompt.Prompt, err = p.Expand(prompt.Prompt, source); err != nil {
+		return err
+	}
+
+	if len(prompt.Messages) > 0 {
+		messages := make([]Message, len(prompt.Messages))
+		for i, message := range prompt.Messages {
+			messages[i] = message
+			if messages[i].Content, err = p.Expand(message.Content, source); err != nil {
+				return err
+			}
+		}
+		prompt.Messages = messages
+	}
+
+	if len(prompt.Blocks) > 0 {
+		blocks := make(map[string]string, len(prompt.Blocks))
+		for name, content := range prompt.Blocks {
+			if blocks[name], err = p.Expand(content, source); err != nil {
+				return err
+			}
+		}
+		prompt.Blocks = blocks
+	}
+
+	return nil
+}
+
+// expand expands includes in content; stack holds the names of the partials
+// currently being expanded and is used to detect cycles
+func (p Partials) expand(content, source string, stack []string) (string, error) {
+	matches := includePattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, nil
+	}
+
+	var builder strings.Builder
+	position := 0
+
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		name := content[match[2]:match[3]]
+
+		partial, exists := p[name]
+		if !exists {
+			return "", fmt.Errorf("partial '%s' not found (included from %s)", name, source)
+		}
+		if containsString(stack, name) {
+			cycle := append(append([]string{}, stack...), name)
+			return "", fmt.Errorf("include cycle %s (included from %s)", strings.Join(cycle, " -> "), source)
+		}
+
+		expanded, err := p.expand(partial.Content, partial.FilePath, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		expanded = strings.TrimRight(expanded, "\n")
+
+		lineStart, lineEnd := standaloneSpan(content, position, start, end)
+		if lineStart != start || lineEnd != end {
+			// Standalone include: indent every line and keep the line break
+			indent := content[lineStart:start]
+			expanded = indentLines(expanded, indent) + "\n"
+			start, end = lineStart, lineEnd
+		}
+
+		builder.WriteString(content[position:start])
+		builder.WriteString(expanded)
+		position = end
+	}
+
+	builder.WriteString(content[position:])
+	return builder.String(), nil
+}
+
+// indentLines prefixes every non-empty line of text with indent
+func indentLines(text, indent string) string {
+	if indent == "" {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,167 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to dir/name, creating parent directories
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+const includingPrompt = `metadata:
+  id: "review"
+  name: "Review"
+  description: "Reviews code"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "code"
+    description: "Code to review"
+    type: "string"
+    required: true
+
+prompt: |
+  Review this code:
+  {{> review/checklist}}
+  Code: {{> code_block}}
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+func TestLoadPromptWithPartials(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "partials/review/checklist.md", "Please provide:\n  {{> best_practices}}\n")
+	writeFile(t, dir, "partials/best_practices.md", "- Tests\n- Docs\n")
+	writeFile(t, dir, "partials/code_block.md", "{{code | fence}}\n")
+	path := writeFile(t, dir, "dev/review.yaml", includingPrompt)
+
+	loader := NewLoader(dir)
+	library, err := loader.LoadAllPrompts()
+	if err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+	if library.Len() != 1 {
+		t.Fatalf("Expected partials not to be loaded as prompts, got %d prompts", library.Len())
+	}
+
+	p, _ := library.GetPrompt("review")
+	expected := "Review this code:\nPlease provide:\n  - Tests\n  - Docs\nCode: {{code | fence}}\n"
+	if p.Prompt != expected {
+		t.Errorf("Expected %q, got %q", expected, p.Prompt)
+	}
+
+	single, err := loader.LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("Failed to load prompt: %v", err)
+	}
+	if single.Prompt != expected {
+		t.Errorf("Expected LoadPrompt to expand includes, got %q", single.Prompt)
+	}
+}
+
+func TestPartialsInBlocks(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "partials/go_checks.md", "1. Error handling\n2. Context cancellation\n")
+	writeFile(t, dir, "review/base.yaml", basePrompt)
+	writeFile(t, dir, "review/go.yaml", strings.Replace(goPrompt, "    1. Error handling\n", "    {{> go_checks}}\n", 1))
+
+	library, err := NewLoader(dir).LoadAllPrompts()
+	if err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	p, _ := library.GetPrompt("go-review")
+	expected := "Please check:\n1. Error handling\n2. Context cancellation\n2. Goroutine leaks"
+	if !strings.Contains(p.Prompt, expected) {
+		t.Errorf("Expected block includes to be expanded, got %q", p.Prompt)
+	}
+}
+
+func TestPartialErrors(t *testing.T) {
+	t.Run("missing partial", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "partials/review/checklist.md", "{{> nowhere}}")
+		writeFile(t, dir, "partials/code_block.md", "{{code}}")
+		writeFile(t, dir, "dev/review.yaml", includingPrompt)
+
+		_, err := NewLoader(dir).LoadAllPrompts()
+		if err == nil {
+			t.Fatal("Expected missing partial to be reported")
+		}
+		location := filepath.Join(dir, "partials", "review", "checklist.md")
+		if !strings.Contains(err.Error(), "partial 'nowhere' not found (included from "+location+")") {
+			t.Errorf("Expected error naming the including file, got: %v", err)
+		}
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "partials/review/checklist.md", "{{> code_block}}")
+		writeFile(t, dir, "partials/code_block.md", "{{> review/checklist}}")
+		writeFile(t, dir, "dev/review.yaml", includingPrompt)
+
+		_, err := NewLoader(dir).LoadAllPrompts()
+		if err == nil || !strings.Contains(err.Error(), "include cycle review/checklist -> code_block -> review/checklist") {
+			t.Errorf("Expected include cycle to be reported, got: %v", err)
+		}
+	})
+
+	t.Run("partial content validated", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "partials/review/checklist.md", "Checklist")
+		writeFile(t, dir, "partials/code_block.md", "{{undeclared}}")
+		writeFile(t, dir, "dev/review.yaml", includingPrompt)
+
+		_, err := NewLoader(dir).LoadAllPrompts()
+		if err == nil || !strings.Contains(err.Error(), "undefined variable 'undeclared'") {
+			t.Errorf("Expected partial content to be validated, got: %v", err)
+		}
+	})
+}
+
+func TestExpandPartials(t *testing.T) {
+	partials := Partials{
+		"list": {Name: "list", Content: "- a\n- b\n", FilePath: "list.md"},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"no includes", "plain {{name}}", "plain {{name}}"},
+		{"inline", "Items: {{>list}} end", "Items: - a\n- b end"},
+		{"standalone", "Items:\n{{> list}}\nend", "Items:\n- a\n- b\nend"},
+		{"standalone indented", "Items:\n    {{> list }}\nend", "Items:\n    - a\n    - b\nend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := partials.Expand(tt.content, "prompt.yaml")
+			if err != nil {
+				t.Fatalf("Failed to expand: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
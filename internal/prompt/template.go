@@ -0,0 +1,590 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Template syntax
+//
+//	{{name}}                          substitutes an argument value
+//	{{name | upper | indent 2}}       substitutes a value passed through filters
+//	{{#if name}} ... {{/if}}          renders the block when name is truthy
+//	{{#if name}} ... {{else}} ... {{/if}}
+//	{{#if !name}} ... {{/if}}         negated condition
+//	{{#unless name}} ... {{/unless}}  shorthand for {{#if !name}}
+//	{{#each name}} ... {{/each}}      renders the block once per array item
+//	{{#each name as item}} ... {{/each}}
+//	{{#each name}} ... {{else}} ... {{/each}}  else renders for an empty array
+//
+// Inside an each block {{this}} (or the name given with "as") is the current
+// item, and {{@index}} (from 0), {{@number}} (from 1), {{@first}} and
+// {{@last}} describe its position. They refer to the innermost loop unless a
+// named item from an outer loop is used.
+//
+// Filters are applied left to right. Arguments are bare words or numbers, or
+// double-quoted strings: {{name | default "unknown"}}. Missing variables are
+// only replaced by the default filter; see filters.go for the full list.
+//
+// Block tags that sit alone on a line consume that whole line, so they do not
+// leave blank lines in the rendered output. Any other {{...}} text is left
+// untouched.
+
+var (
+	tagPattern      = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	variablePattern = regexp.MustCompile(`^(@?\w+)$`)
+	filteredPattern = regexp.MustCompile(`^(@?\w+)\s*\|(.*)$`)
+	openPattern     = regexp.MustCompile(`^#(if|unless)\s+(!?)(@?\w+)$`)
+	eachPattern     = regexp.MustCompile(`^#each\s+(\w+)(?:\s+as\s+(\w+))?$`)
+	closePattern    = regexp.MustCompile(`^/(if|unless|each)$`)
+)
+
+// loopVariables are the variables defined inside every each block
+var loopVariables = map[string]bool{
+	"this":    true,
+	"@index":  true,
+	"@number": true,
+	"@first":  true,
+	"@last":   true,
+}
+
+// Template is a parsed prompt template
+type Template struct {
+	nodes []node
+}
+
+// node is an element of a parsed template
+type node interface{}
+
+// textNode is literal template text
+type textNode struct {
+	text string
+}
+
+// variableNode substitutes the value of a variable
+type variableNode struct {
+	name    string
+	filters []filterCall
+	raw     string // original tag, rendered when the variable has no value
+	line    int
+}
+
+// conditionalNode renders one of two branches based on a variable
+type conditionalNode struct {
+	name   string
+	negate bool
+	then   []node
+	orElse []node
+	line   int
+}
+
+// eachNode renders its body once per item of an array variable
+type eachNode struct {
+	name   string
+	alias  string // optional name for the current item
+	body   []node
+	orElse []node // rendered when the array is empty
+	line   int
+}
+
+// tokenKind classifies template tags
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenVariable
+	tokenOpen
+	tokenElse
+	tokenClose
+)
+
+// token is a lexical element of a template
+type token struct {
+	kind   tokenKind
+	text   string // literal text or the original tag
+	block  string // block keyword for open and close tags
+	name   string // variable name
+	alias  string // item name for each blocks
+	pipe   string // filters following the variable name
+	negate bool
+	line   int
+}
+
+// ParseTemplate parses prompt content into a Template
+func ParseTemplate(content string) (*Template, error) {
+	p := &parser{tokens: tokenize(content)}
+
+	nodes, stop, err := p.parseNodes()
+	if err != nil {
+		return nil, err
+	}
+	if stop != nil {
+		return nil, unexpectedTagError(*stop)
+	}
+
+	if err := checkScopes(nodes, nil, 0); err != nil {
+		return nil, err
+	}
+
+	return &Template{nodes: nodes}, nil
+}
+
+// tokenize splits content into text and tag tokens
+func tokenize(content string) []token {
+	var tokens []token
+	position := 0
+
+	for _, match := range tagPattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := match[0], match[1]
+		inner := content[match[2]:match[3]]
+		line := strings.Count(content[:start], "\n") + 1
+
+		tok, ok := classifyTag(inner)
+		if !ok {
+			// Not template syntax; keep it as literal text
+			continue
+		}
+		tok.text = content[start:end]
+		tok.line = line
+
+		if tok.kind != tokenVariable {
+			start, end = standaloneSpan(content, position, start, end)
+		}
+
+		if start > position {
+			tokens = append(tokens, token{kind: tokenText, text: content[position:start]})
+		}
+		tokens = append(tokens, tok)
+		position = end
+	}
+
+	if position < len(content) {
+		tokens = append(tokens, token{kind: tokenText, text: content[position:]})
+	}
+
+	return tokens
+}
+
+// classifyTag recognises the inner text of a {{...}} tag
+func classifyTag(inner string) (token, bool) {
+	if m := variablePattern.FindStringSubmatch(inner); m != nil {
+		if m[1] == "else" {
+			return token{kind: tokenElse}, true
+		}
+		return token{kind: tokenVariable, name: m[1]}, true
+	}
+
+	if m := filteredPattern.FindStringSubmatch(inner); m != nil {
+		return token{kind: tokenVariable, name: m[1], pipe: m[2]}, true
+	}
+
+	if m := openPattern.FindStringSubmatch(inner); m != nil {
+		negate := m[2] == "!"
+		if m[1] == "unless" {
+			negate = !negate
+		}
+		return token{kind: tokenOpen, block: m[1], name: m[3], negate: negate}, true
+	}
+
+	if m := eachPattern.FindStringSubmatch(inner); m != nil {
+		return token{kind: tokenOpen, block: "each", name: m[1], alias: m[2]}, true
+	}
+
+	if m := closePattern.FindStringSubmatch(inner); m != nil {
+		return token{kind: tokenClose, block: m[1]}, true
+	}
+
+	return token{}, false
+}
+
+// standaloneSpan widens a block tag at [start, end) to cover its whole line
+// when the tag is the only thing on that line
+func standaloneSpan(content string, minStart, start, end int) (int, int) {
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	if lineStart < minStart || strings.TrimSpace(content[lineStart:start]) != "" {
+		return start, end
+	}
+
+	lineEnd := len(content)
+	if i := strings.Index(content[end:], "\n"); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	if strings.TrimSpace(content[end:lineEnd]) != "" {
+		return start, end
+	}
+
+	return lineStart, lineEnd
+}
+
+// parser builds the node tree from tokens
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parseNodes parses nodes until the end of input or an else/close tag, which
+// is returned unconsumed for the enclosing block to handle
+func (p *parser) parseNodes() ([]node, *token, error) {
+	var nodes []node
+
+	for p.pos < len(p.tokens) {
+		tok := p.tokens[p.pos]
+
+		switch tok.kind {
+		case tokenText:
+			nodes = append(nodes, textNode{text: tok.text})
+			p.pos++
+
+		case tokenVariable:
+			var calls []filterCall
+			if tok.pipe != "" {
+				var err error
+				if calls, err = parsePipeline(tok.pipe); err != nil {
+					return nil, nil, fmt.Errorf("line %d: %s: %w", tok.line, tok.text, err)
+				}
+			}
+			nodes = append(nodes, variableNode{name: tok.name, filters: calls, raw: tok.text, line: tok.line})
+			p.pos++
+
+		case tokenOpen:
+			p.pos++
+			block, err := p.parseBlock(tok)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, block)
+
+		case tokenElse, tokenClose:
+			return nodes, &tok, nil
+		}
+	}
+
+	return nodes, nil, nil
+}
+
+// parseBlock parses the body of a block opened by open
+func (p *parser) parseBlock(open token) (node, error) {
+	body, stop, err := p.parseNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	var orElse []node
+	if stop != nil && stop.kind == tokenElse {
+		p.pos++
+		orElse, stop, err = p.parseNodes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stop == nil {
+		return nil, fmt.Errorf("line %d: %s is never closed", open.line, open.text)
+	}
+	if stop.kind != tokenClose || stop.block != open.block {
+		return nil, unexpectedTagError(*stop)
+	}
+	p.pos++
+
+	if open.block == "each" {
+		return eachNode{name: open.name, alias: open.alias, body: body, orElse: orElse, line: open.line}, nil
+	}
+	return conditionalNode{name: open.name, negate: open.negate, then: body, orElse: orElse, line: open.line}, nil
+}
+
+// unexpectedTagError reports a tag that does not belong where it appears
+func unexpectedTagError(tok token) error {
+	return fmt.Errorf("line %d: unexpected %s", tok.line, tok.text)
+}
+
+// checkScopes rejects loop variables used outside of a loop and item names
+// that clash with loop variables or the names of enclosing loops
+func checkScopes(nodes []node, aliases []string, depth int) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case variableNode:
+			if err := checkLoopVariable(n.name, n.line, depth); err != nil {
+				return err
+			}
+
+		case conditionalNode:
+			if err := checkLoopVariable(n.name, n.line, depth); err != nil {
+				return err
+			}
+			if err := checkScopes(n.then, aliases, depth); err != nil {
+				return err
+			}
+			if err := checkScopes(n.orElse, aliases, depth); err != nil {
+				return err
+			}
+
+		case eachNode:
+			inner := aliases
+			if n.alias != "" {
+				if loopVariables[n.alias] || containsString(aliases, n.alias) {
+					return fmt.Errorf("line %d: loop item name '%s' is already in use", n.line, n.alias)
+				}
+				inner = append(append([]string{}, aliases...), n.alias)
+			}
+			if containsString(aliases, n.name) {
+				return fmt.Errorf("line %d: cannot loop over loop item '%s'", n.line, n.name)
+			}
+			if err := checkScopes(n.body, inner, depth+1); err != nil {
+				return err
+			}
+			// The else branch renders outside of the loop
+			if err := checkScopes(n.orElse, aliases, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkLoopVariable rejects loop variables used outside of any loop
+func checkLoopVariable(name string, line, depth int) error {
+	if loopVariables[name] && depth == 0 {
+		return fmt.Errorf("line %d: {{%s}} can only be used inside {{#each}}", line, name)
+	}
+	if strings.HasPrefix(name, "@") && !loopVariables[name] {
+		return fmt.Errorf("line %d: unknown loop variable {{%s}}", line, name)
+	}
+	return nil
+}
+
+// Variables returns the names of all argument variables referenced by the
+// template, including conditions and loop sources but not loop variables,
+// sorted and without duplicates
+func (t *Template) Variables() []string {
+	seen := make(map[string]bool)
+	collectVariables(t.nodes, nil, seen)
+	return sortedKeys(seen)
+}
+
+// Loops returns the names of the variables iterated by each blocks, sorted
+// and without duplicates
+func (t *Template) Loops() []string {
+	seen := make(map[string]bool)
+	collectLoops(t.nodes, seen)
+	return sortedKeys(seen)
+}
+
+// collectVariables records the argument variables referenced by nodes in
+// seen, skipping loop variables and the item names in aliases
+func collectVariables(nodes []node, aliases []string, seen map[string]bool) {
+	record := func(name string) {
+		if !loopVariables[name] && !containsString(aliases, name) {
+			seen[name] = true
+		}
+	}
+
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case variableNode:
+			record(n.name)
+		case conditionalNode:
+			record(n.name)
+			collectVariables(n.then, aliases, seen)
+			collectVariables(n.orElse, aliases, seen)
+		case eachNode:
+			record(n.name)
+			inner := aliases
+			if n.alias != "" {
+				inner = append(append([]string{}, aliases...), n.alias)
+			}
+			collectVariables(n.body, inner, seen)
+			collectVariables(n.orElse, aliases, seen)
+		}
+	}
+}
+
+// collectLoops records the variables iterated by each blocks in seen
+func collectLoops(nodes []node, seen map[string]bool) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case conditionalNode:
+			collectLoops(n.then, seen)
+			collectLoops(n.orElse, seen)
+		case eachNode:
+			seen[n.name] = true
+			collectLoops(n.body, seen)
+			collectLoops(n.orElse, seen)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render renders the template with values. Variables without a value are
+// rendered as their original tag and count as false in conditions.
+func (t *Template) Render(values map[string]interface{}) (string, error) {
+	var builder strings.Builder
+	if err := renderNodes(&builder, t.nodes, &scope{values: values}); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// scope resolves variables during rendering
+type scope struct {
+	values map[string]interface{}
+	loops  []loopFrame // innermost last
+}
+
+// loopFrame describes the current iteration of an each block
+type loopFrame struct {
+	alias string
+	item  interface{}
+	index int
+	count int
+}
+
+// lookup resolves a variable against the loop frames and then the values
+func (sc *scope) lookup(name string) (interface{}, bool) {
+	if len(sc.loops) > 0 && loopVariables[name] {
+		frame := sc.loops[len(sc.loops)-1]
+		switch name {
+		case "this":
+			return frame.item, true
+		case "@index":
+			return frame.index, true
+		case "@number":
+			return frame.index + 1, true
+		case "@first":
+			return frame.index == 0, true
+		case "@last":
+			return frame.index == frame.count-1, true
+		}
+	}
+
+	for i := len(sc.loops) - 1; i >= 0; i-- {
+		if sc.loops[i].alias != "" && sc.loops[i].alias == name {
+			return sc.loops[i].item, true
+		}
+	}
+
+	value, exists := sc.values[name]
+	return value, exists
+}
+
+// renderNodes writes nodes to builder
+func renderNodes(builder *strings.Builder, nodes []node, sc *scope) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case textNode:
+			builder.WriteString(n.text)
+
+		case variableNode:
+			value, exists := sc.lookup(n.name)
+			value, exists, err := applyFilters(value, exists, n.filters)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.line, err)
+			}
+			if exists {
+				builder.WriteString(formatValue(value))
+			} else {
+				builder.WriteString(n.raw)
+			}
+
+		case conditionalNode:
+			value, _ := sc.lookup(n.name)
+			branch := n.orElse
+			if isTruthy(value) != n.negate {
+				branch = n.then
+			}
+			if err := renderNodes(builder, branch, sc); err != nil {
+				return err
+			}
+
+		case eachNode:
+			value, _ := sc.lookup(n.name)
+			items, err := loopItems(value)
+			if err != nil {
+				return fmt.Errorf("line %d: cannot loop over '%s': %w", n.line, n.name, err)
+			}
+
+			if len(items) == 0 {
+				if err := renderNodes(builder, n.orElse, sc); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for i, item := range items {
+				sc.loops = append(sc.loops, loopFrame{alias: n.alias, item: item, index: i, count: len(items)})
+				err := renderNodes(builder, n.body, sc)
+				sc.loops = sc.loops[:len(sc.loops)-1]
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// loopItems returns the items of an array value. A missing value is treated
+// as an empty array.
+func loopItems(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("value is not an array")
+	}
+}
+
+// formatValue formats a value for substitution. Arrays are joined with
+// commas rather than printed in Go syntax.
+func formatValue(value interface{}) string {
+	if items, err := loopItems(value); err == nil && value != nil {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = formatValue(item)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// isTruthy reports whether a value enables a conditional block
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case []interface{}:
+		return len(v) > 0
+	case []string:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,350 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "variable",
+			content:  "Hello {{name}}!",
+			values:   map[string]interface{}{"name": "team"},
+			expected: "Hello team!",
+		},
+		{
+			name:     "missing variable kept",
+			content:  "Hello {{name}}!",
+			values:   map[string]interface{}{},
+			expected: "Hello {{name}}!",
+		},
+		{
+			name:     "non-template braces kept",
+			content:  "Use {{ name }} or {{a-b}} in Jinja",
+			values:   map[string]interface{}{"name": "x"},
+			expected: "Use {{ name }} or {{a-b}} in Jinja",
+		},
+		{
+			name:     "inline if true",
+			content:  "Review{{#if strict}} strictly{{/if}}.",
+			values:   map[string]interface{}{"strict": true},
+			expected: "Review strictly.",
+		},
+		{
+			name:     "inline if false",
+			content:  "Review{{#if strict}} strictly{{/if}}.",
+			values:   map[string]interface{}{"strict": false},
+			expected: "Review.",
+		},
+		{
+			name:     "else branch",
+			content:  "{{#if formal}}Dear {{name}}{{else}}Hi {{name}}{{/if}}",
+			values:   map[string]interface{}{"formal": false, "name": "Sam"},
+			expected: "Hi Sam",
+		},
+		{
+			name:     "negation",
+			content:  "{{#if !quick}}Be thorough.{{/if}}",
+			values:   map[string]interface{}{"quick": false},
+			expected: "Be thorough.",
+		},
+		{
+			name:     "unless",
+			content:  "{{#unless quick}}Be thorough.{{else}}Be brief.{{/unless}}",
+			values:   map[string]interface{}{"quick": true},
+			expected: "Be brief.",
+		},
+		{
+			name:     "missing condition is false",
+			content:  "{{#if flag}}yes{{else}}no{{/if}}",
+			values:   map[string]interface{}{},
+			expected: "no",
+		},
+		{
+			name:     "string condition",
+			content:  "{{#if context}}Context: {{context}}{{/if}}",
+			values:   map[string]interface{}{"context": ""},
+			expected: "",
+		},
+		{
+			name: "standalone block lines removed",
+			content: `Please provide:
+1. Overview
+  {{#if include_security}}
+2. Security review
+  {{/if}}
+3. Summary
+`,
+			values:   map[string]interface{}{"include_security": true},
+			expected: "Please provide:\n1. Overview\n2. Security review\n3. Summary\n",
+		},
+		{
+			name: "standalone block dropped",
+			content: `1. Overview
+{{#if include_security}}
+2. Security review
+{{/if}}
+3. Summary`,
+			values:   map[string]interface{}{"include_security": false},
+			expected: "1. Overview\n3. Summary",
+		},
+		{
+			name:     "nested blocks",
+			content:  "{{#if a}}A{{#if b}}B{{else}}!B{{/if}}{{/if}}",
+			values:   map[string]interface{}{"a": true, "b": false},
+			expected: "A!B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseTemplate(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse template: %v", err)
+			}
+
+			result, err := template.Render(tt.values)
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"unclosed if", "line one\n{{#if a}}text", "line 2: {{#if a}} is never closed"},
+		{"stray close", "text{{/if}}", "unexpected {{/if}}"},
+		{"stray else", "{{else}}", "unexpected {{else}}"},
+		{"mismatched close", "{{#if a}}x{{/unless}}", "unexpected {{/unless}}"},
+		{"double else", "{{#if a}}x{{else}}y{{else}}z{{/if}}", "unexpected {{else}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.content)
+			if err == nil {
+				t.Fatal("Expected parse error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestTemplateVariables(t *testing.T) {
+	template, err := ParseTemplate("{{#if a}}{{b}}{{else}}{{#unless c}}{{b}}{{/unless}}{{/if}}")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	if got := template.Variables(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c], got %v", got)
+	}
+}
+
+func TestValidatePromptContentConditionals(t *testing.T) {
+	arguments := []Argument{
+		{Name: "include_security", Description: "Add a security section", Type: ArgumentTypeBoolean},
+		{Name: "code", Description: "Code to review", Type: ArgumentTypeString, Required: true},
+	}
+
+	valid := "{{#if include_security}}Check {{code}} for vulnerabilities.{{/if}}"
+	if err := validatePromptContent(valid, arguments); err != nil {
+		t.Errorf("Expected valid content, got: %v", err)
+	}
+
+	undefined := "{{#if include_security}}Check {{missing}}.{{/if}} {{code}}"
+	if err := validatePromptContent(undefined, arguments); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected undefined variable inside block to be reported, got: %v", err)
+	}
+
+	undefinedCondition := "{{#if unknown_flag}}x{{/if}} {{code}}"
+	if err := validatePromptContent(undefinedCondition, arguments); err == nil || !strings.Contains(err.Error(), "unknown_flag") {
+		t.Errorf("Expected undefined condition to be reported, got: %v", err)
+	}
+
+	unclosed := "{{#if include_security}}{{code}}"
+	if err := validatePromptContent(unclosed, arguments); err == nil {
+		t.Error("Expected unclosed block to be rejected")
+	}
+}
+
+func TestRenderEachTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		values   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "items",
+			content:  "{{#each files}}- {{this}}\n{{/each}}",
+			values:   map[string]interface{}{"files": []interface{}{"a.go", "b.go"}},
+			expected: "- a.go\n- b.go\n",
+		},
+		{
+			name:     "index and position",
+			content:  "{{#each steps}}{{@number}}. {{this}}{{#unless @last}}, {{/unless}}{{/each}}",
+			values:   map[string]interface{}{"steps": []string{"plan", "build", "test"}},
+			expected: "1. plan, 2. build, 3. test",
+		},
+		{
+			name:     "named item",
+			content:  "{{#each files as file}}{{@index}}={{file}} {{/each}}",
+			values:   map[string]interface{}{"files": []interface{}{"x", "y"}},
+			expected: "0=x 1=y ",
+		},
+		{
+			name:     "nested loops with outer item",
+			content:  "{{#each langs as lang}}{{#each files}}{{lang}}:{{this}} {{/each}}{{/each}}",
+			values:   map[string]interface{}{"langs": []interface{}{"go", "py"}, "files": []interface{}{"a", "b"}},
+			expected: "go:a go:b py:a py:b ",
+		},
+		{
+			name:     "empty array renders else",
+			content:  "{{#each files}}{{this}}{{else}}No files.{{/each}}",
+			values:   map[string]interface{}{"files": []interface{}{}},
+			expected: "No files.",
+		},
+		{
+			name:     "missing array renders else",
+			content:  "{{#each files}}{{this}}{{else}}No files.{{/each}}",
+			values:   map[string]interface{}{},
+			expected: "No files.",
+		},
+		{
+			name:     "arguments visible inside loop",
+			content:  "{{#each files}}{{prefix}}{{this}} {{/each}}",
+			values:   map[string]interface{}{"files": []interface{}{"a"}, "prefix": "src/"},
+			expected: "src/a ",
+		},
+		{
+			name:     "array variable joined",
+			content:  "Files: {{files}}",
+			values:   map[string]interface{}{"files": []interface{}{"a", "b"}},
+			expected: "Files: a, b",
+		},
+		{
+			name:     "standalone each lines removed",
+			content:  "Files:\n{{#each files}}\n- {{this}}\n{{/each}}\nDone",
+			values:   map[string]interface{}{"files": []interface{}{"a", "b"}},
+			expected: "Files:\n- a\n- b\nDone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := ParseTemplate(tt.content)
+			if err != nil {
+				t.Fatalf("Failed to parse template: %v", err)
+			}
+
+			result, err := template.Render(tt.values)
+			if err != nil {
+				t.Fatalf("Failed to render template: %v", err)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseEachTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"unclosed each", "{{#each files}}{{this}}", "{{#each files}} is never closed"},
+		{"mismatched close", "{{#each files}}x{{/if}}", "unexpected {{/if}}"},
+		{"this outside loop", "text\n{{this}}", "line 2: {{this}} can only be used inside {{#each}}"},
+		{"index outside loop", "{{#if @first}}x{{/if}}", "{{@first}} can only be used inside {{#each}}"},
+		{"index in else branch", "{{#each files}}x{{else}}{{@index}}{{/each}}", "{{@index}} can only be used inside {{#each}}"},
+		{"unknown loop variable", "{{#each files}}{{@key}}{{/each}}", "unknown loop variable {{@key}}"},
+		{"reused item name", "{{#each a as x}}{{#each b as x}}{{/each}}{{/each}}", "loop item name 'x' is already in use"},
+		{"loop over item", "{{#each a as x}}{{#each x}}{{/each}}{{/each}}", "cannot loop over loop item 'x'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.content)
+			if err == nil {
+				t.Fatal("Expected parse error")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %q", tt.message, err.Error())
+			}
+		})
+	}
+}
+
+func TestRenderEachNonArray(t *testing.T) {
+	template, err := ParseTemplate("{{#each files}}{{this}}{{/each}}")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	if _, err := template.Render(map[string]interface{}{"files": "a.go"}); err == nil {
+		t.Error("Expected error when looping over a non-array value")
+	}
+}
+
+func TestTemplateLoopVariables(t *testing.T) {
+	template, err := ParseTemplate("{{#each files as file}}{{file}} {{this}} {{@index}} {{prefix}}{{#each tags}}{{this}}{{/each}}{{/each}}")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	if got := template.Variables(); !reflect.DeepEqual(got, []string{"files", "prefix", "tags"}) {
+		t.Errorf("Expected [files prefix tags], got %v", got)
+	}
+	if got := template.Loops(); !reflect.DeepEqual(got, []string{"files", "tags"}) {
+		t.Errorf("Expected [files tags], got %v", got)
+	}
+}
+
+func TestValidatePromptContentLoops(t *testing.T) {
+	arguments := []Argument{
+		{Name: "files", Description: "Files to review", Type: ArgumentTypeArray, Items: ArgumentTypeString, Required: true},
+		{Name: "focus", Description: "Review focus", Type: ArgumentTypeString},
+	}
+
+	valid := "{{#each files as file}}Review {{file}} for {{focus}}.\n{{/each}}"
+	if err := validatePromptContent(valid, arguments); err != nil {
+		t.Errorf("Expected valid content, got: %v", err)
+	}
+
+	notArray := "{{#each focus}}{{this}}{{/each}} {{files}}"
+	if err := validatePromptContent(notArray, arguments); err == nil || !strings.Contains(err.Error(), "must be array") {
+		t.Errorf("Expected loop over non-array argument to be rejected, got: %v", err)
+	}
+
+	undefined := "{{#each files}}{{this}} {{missing}}{{/each}}"
+	if err := validatePromptContent(undefined, arguments); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected undefined variable inside loop to be reported, got: %v", err)
+	}
+
+	outOfScope := "{{#each files as file}}{{file}}{{/each}} {{file}}"
+	if err := validatePromptContent(outOfScope, arguments); err == nil || !strings.Contains(err.Error(), "file") {
+		t.Errorf("Expected loop item used outside its loop to be reported, got: %v", err)
+	}
+}
@@ -4,93 +4,138 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
-// ValidatePrompt validates a prompt structure
+// ValidatePrompt validates a prompt structure and returns the first problem
+// found
 func ValidatePrompt(prompt *Prompt) error {
-	if err := validateMetadata(&prompt.Metadata); err != nil {
-		return fmt.Errorf("metadata validation failed: %w", err)
+	if errs := ValidatePromptAll(prompt); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidatePromptAll validates a prompt structure and returns every problem
+// found: each invalid metadata field, the first problem of each argument and
+// the first problem of the template or messages
+func ValidatePromptAll(prompt *Prompt) []error {
+	var errs []error
+
+	for _, err := range metadataErrors(&prompt.Metadata) {
+		errs = append(errs, fmt.Errorf("metadata validation failed: %w", err))
 	}
 
-	if err := validateArguments(prompt.Arguments); err != nil {
-		return fmt.Errorf("arguments validation failed: %w", err)
+	for _, err := range argumentErrors(prompt.Arguments) {
+		errs = append(errs, fmt.Errorf("arguments validation failed: %w", err))
+	}
+
+	if len(prompt.Messages) > 0 {
+		if strings.TrimSpace(prompt.Prompt) != "" {
+			return append(errs, errors.New("prompt and messages cannot both be set"))
+		}
+		if err := validateMessages(prompt.Messages, prompt.Arguments); err != nil {
+			errs = append(errs, fmt.Errorf("messages validation failed: %w", err))
+		}
+		return errs
 	}
 
 	if err := validatePromptContent(prompt.Prompt, prompt.Arguments); err != nil {
-		return fmt.Errorf("prompt content validation failed: %w", err)
+		errs = append(errs, fmt.Errorf("prompt content validation failed: %w", err))
 	}
 
-	return nil
+	return errs
 }
 
-// validateMetadata validates prompt metadata
-func validateMetadata(metadata *Metadata) error {
-	if strings.TrimSpace(metadata.ID) == "" {
-		return errors.New("id is required")
-	}
+// metadataErrors returns a problem for each invalid metadata field
+func metadataErrors(metadata *Metadata) []error {
+	var errs []error
 
-	if !isValidID(metadata.ID) {
-		return errors.New("id must contain only alphanumeric characters, hyphens, and underscores")
+	if strings.TrimSpace(metadata.ID) == "" {
+		errs = append(errs, errors.New("id is required"))
+	} else if !isValidID(metadata.ID) {
+		errs = append(errs, errors.New("id must contain only alphanumeric characters, hyphens, and underscores"))
 	}
 
 	if strings.TrimSpace(metadata.Name) == "" {
-		return errors.New("name is required")
+		errs = append(errs, errors.New("name is required"))
 	}
 
 	if strings.TrimSpace(metadata.Description) == "" {
-		return errors.New("description is required")
+		errs = append(errs, errors.New("description is required"))
 	}
 
 	if strings.TrimSpace(metadata.Author) == "" {
-		return errors.New("author is required")
+		errs = append(errs, errors.New("author is required"))
 	}
 
 	if strings.TrimSpace(metadata.Version) == "" {
-		return errors.New("version is required")
+		errs = append(errs, errors.New("version is required"))
+	} else if _, err := ParseVersion(metadata.Version); err != nil {
+		errs = append(errs, err)
 	}
 
 	if metadata.Created.IsZero() {
-		return errors.New("created timestamp is required")
+		errs = append(errs, errors.New("created timestamp is required"))
 	}
 
 	if metadata.Modified.IsZero() {
-		return errors.New("modified timestamp is required")
+		errs = append(errs, errors.New("modified timestamp is required"))
 	}
 
-	return nil
+	return errs
 }
 
-// validateArguments validates prompt arguments
-func validateArguments(arguments []Argument) error {
+// argumentErrors returns the first problem of each invalid argument
+func argumentErrors(arguments []Argument) []error {
+	var errs []error
 	namesSeen := make(map[string]bool)
 
 	for i, arg := range arguments {
-		if strings.TrimSpace(arg.Name) == "" {
-			return fmt.Errorf("argument %d: name is required", i)
+		if err := validateArgument(i, arg, namesSeen); err != nil {
+			errs = append(errs, err)
 		}
+	}
 
-		if !isValidArgumentName(arg.Name) {
-			return fmt.Errorf("argument %d: name must contain only alphanumeric characters and underscores", i)
-		}
+	return errs
+}
 
-		if namesSeen[arg.Name] {
-			return fmt.Errorf("argument %d: duplicate name '%s'", i, arg.Name)
-		}
-		namesSeen[arg.Name] = true
+// validateArgument validates the argument at index i. namesSeen holds the
+// names of the preceding arguments.
+func validateArgument(i int, arg Argument, namesSeen map[string]bool) error {
+	if strings.TrimSpace(arg.Name) == "" {
+		return fmt.Errorf("argument %d: name is required", i)
+	}
 
-		if strings.TrimSpace(arg.Description) == "" {
-			return fmt.Errorf("argument %d (%s): description is required", i, arg.Name)
-		}
+	if !isValidArgumentName(arg.Name) {
+		return fmt.Errorf("argument %d: name must contain only alphanumeric characters and underscores", i)
+	}
 
-		if !isValidArgumentType(arg.Type) {
-			return fmt.Errorf("argument %d (%s): invalid type '%s'", i, arg.Name, arg.Type)
-		}
+	if namesSeen[arg.Name] {
+		return fmt.Errorf("argument %d: duplicate name '%s'", i, arg.Name)
+	}
+	namesSeen[arg.Name] = true
 
-		if arg.Default != nil {
-			if err := validateArgumentDefault(arg.Default, arg.Type); err != nil {
-				return fmt.Errorf("argument %d (%s): %w", i, arg.Name, err)
-			}
+	if strings.TrimSpace(arg.Description) == "" {
+		return fmt.Errorf("argument %d (%s): description is required", i, arg.Name)
+	}
+
+	if !isValidArgumentType(arg.Type) {
+		return fmt.Errorf("argument %d (%s): invalid type '%s'", i, arg.Name, arg.Type)
+	}
+
+	if err := validateArgumentShape(arg); err != nil {
+		return fmt.Errorf("argument %d (%s): %w", i, arg.Name, err)
+	}
+
+	if err := validateArgumentCompletion(arg); err != nil {
+		return fmt.Errorf("argument %d (%s): %w", i, arg.Name, err)
+	}
+
+	if arg.Default != nil {
+		if err := validateArgumentDefault(arg); err != nil {
+			return fmt.Errorf("argument %d (%s): %w", i, arg.Name, err)
 		}
 	}
 
@@ -103,17 +148,102 @@ func validatePromptContent(content string, arguments []Argument) error {
 		return errors.New("prompt content is required")
 	}
 
-	// Extract variable placeholders from the prompt
-	variablePattern := regexp.MustCompile(`\{\{(\w+)\}\}`)
-	matches := variablePattern.FindAllStringSubmatch(content, -1)
+	usedVariables, err := validateTemplate(content, arguments)
+	if err != nil {
+		return err
+	}
 
+	// Check for unused required arguments
+	for _, arg := range arguments {
+		if arg.Required && !usedVariables[arg.Name] {
+			return fmt.Errorf("required argument '%s' is not used in prompt", arg.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateMessages validates the messages of a multi-message prompt. Every
+// required argument must be used by at least one message.
+func validateMessages(messages []Message, arguments []Argument) error {
 	usedVariables := make(map[string]bool)
-	for _, match := range matches {
-		if len(match) > 1 {
-			usedVariables[match[1]] = true
+
+	for i, message := range messages {
+		if !isValidMessageRole(message.Role) {
+			return fmt.Errorf("message %d: invalid role '%s', must be one of: system, user, assistant", i, message.Role)
+		}
+
+		template, err := validateMessageShape(message)
+		if err != nil {
+			return fmt.Errorf("message %d (%s): %w", i, message.Role, err)
+		}
+
+		used, err := validateTemplate(template, arguments)
+		if err != nil {
+			return fmt.Errorf("message %d (%s): %w", i, message.Role, err)
+		}
+		for variable := range used {
+			usedVariables[variable] = true
 		}
 	}
 
+	// Check for unused required arguments
+	for _, arg := range arguments {
+		if arg.Required && !usedVariables[arg.Name] {
+			return fmt.Errorf("required argument '%s' is not used in any message", arg.Name)
+		}
+	}
+
+	return nil
+}
+
+// validateMessageShape checks the fields of a message against its type and
+// returns the template to validate: the content of text messages or the URI
+// of resource messages
+func validateMessageShape(message Message) (string, error) {
+	switch message.Type {
+	case "", MessageTypeText:
+		if message.URI != "" {
+			return "", errors.New("uri is only allowed for resource messages")
+		}
+		if strings.TrimSpace(message.Content) == "" {
+			return "", errors.New("content is required")
+		}
+		return message.Content, nil
+
+	case MessageTypeResource:
+		if message.Content != "" {
+			return "", errors.New("content is not allowed for resource messages")
+		}
+		if strings.TrimSpace(message.URI) == "" {
+			return "", errors.New("uri is required for resource messages")
+		}
+		for _, scheme := range EmbedSchemes {
+			if strings.HasPrefix(message.URI, scheme) {
+				return message.URI, nil
+			}
+		}
+		return "", fmt.Errorf("uri must start with one of: %s", strings.Join(EmbedSchemes, ", "))
+
+	default:
+		return "", fmt.Errorf("invalid type '%s', must be text or resource", message.Type)
+	}
+}
+
+// validateTemplate checks that a template parses and only uses declared
+// arguments, and returns the variables it references
+func validateTemplate(content string, arguments []Argument) (map[string]bool, error) {
+	template, err := ParseTemplate(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	// Extract variables referenced by placeholders and conditions
+	usedVariables := make(map[string]bool)
+	for _, variable := range template.Variables() {
+		usedVariables[variable] = true
+	}
+
 	// Create a map of defined arguments
 	definedArgs := make(map[string]bool)
 	for _, arg := range arguments {
@@ -123,18 +253,30 @@ func validatePromptContent(content string, arguments []Argument) error {
 	// Check for undefined variables in prompt
 	for variable := range usedVariables {
 		if !definedArgs[variable] {
-			return fmt.Errorf("undefined variable '%s' used in prompt", variable)
+			return nil, fmt.Errorf("undefined variable '%s' used in prompt", variable)
 		}
 	}
 
-	// Check for unused required arguments
-	for _, arg := range arguments {
-		if arg.Required && !usedVariables[arg.Name] {
-			return fmt.Errorf("required argument '%s' is not used in prompt", arg.Name)
+	// Loops can only iterate over array arguments
+	for _, name := range template.Loops() {
+		for _, arg := range arguments {
+			if arg.Name == name && arg.Type != ArgumentTypeArray {
+				return nil, fmt.Errorf("cannot loop over argument '%s' of type %s, must be array", name, arg.Type)
+			}
 		}
 	}
 
-	return nil
+	return usedVariables, nil
+}
+
+// isValidMessageRole checks if a message role is supported
+func isValidMessageRole(role MessageRole) bool {
+	switch role {
+	case MessageRoleSystem, MessageRoleUser, MessageRoleAssistant:
+		return true
+	default:
+		return false
+	}
 }
 
 // isValidID checks if an ID is valid (alphanumeric, hyphens, underscores)
@@ -152,31 +294,143 @@ func isValidArgumentName(name string) bool {
 // isValidArgumentType checks if an argument type is valid
 func isValidArgumentType(argType ArgumentType) bool {
 	switch argType {
-	case ArgumentTypeString, ArgumentTypeNumber, ArgumentTypeBoolean:
+	case ArgumentTypeString, ArgumentTypeNumber, ArgumentTypeBoolean, ArgumentTypeEnum, ArgumentTypeArray:
 		return true
 	default:
 		return false
 	}
 }
 
+// isValidItemType checks if a type can be used for array items
+func isValidItemType(itemType ArgumentType) bool {
+	return itemType != ArgumentTypeArray && isValidArgumentType(itemType)
+}
+
+// validateArgumentShape validates the type-specific fields of an argument
+func validateArgumentShape(arg Argument) error {
+	if arg.Type == ArgumentTypeArray {
+		if arg.Items == "" {
+			return errors.New("array arguments must declare an items type")
+		}
+		if !isValidItemType(arg.Items) {
+			return fmt.Errorf("invalid items type '%s'", arg.Items)
+		}
+	} else if arg.Items != "" {
+		return errors.New("items is only allowed for array arguments")
+	}
+
+	isEnum := arg.Type == ArgumentTypeEnum || (arg.Type == ArgumentTypeArray && arg.Items == ArgumentTypeEnum)
+	if !isEnum {
+		if len(arg.Values) > 0 {
+			return errors.New("values is only allowed for enum arguments")
+		}
+		return nil
+	}
+
+	if len(arg.Values) == 0 {
+		return errors.New("enum arguments must declare at least one value")
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range arg.Values {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("enum values must not be empty")
+		}
+		if seen[value] {
+			return fmt.Errorf("duplicate enum value '%s'", value)
+		}
+		seen[value] = true
+	}
+
+	return nil
+}
+
+// validateArgumentCompletion validates the suggestions and completion source
+// of an argument against its value type
+func validateArgumentCompletion(arg Argument) error {
+	valueType := arg.ValueType()
+
+	if len(arg.Suggestions) > 0 && valueType == ArgumentTypeEnum {
+		return errors.New("suggestions are not allowed for enum arguments, use values")
+	}
+
+	for _, suggestion := range arg.Suggestions {
+		var err error
+		switch valueType {
+		case ArgumentTypeNumber:
+			_, err = strconv.ParseFloat(suggestion, 64)
+		case ArgumentTypeBoolean:
+			_, err = strconv.ParseBool(suggestion)
+		}
+		if err != nil || strings.TrimSpace(suggestion) == "" {
+			return fmt.Errorf("suggestion '%s' is not a valid %s", suggestion, valueType)
+		}
+	}
+
+	switch arg.Completion {
+	case "":
+	case CompletionPath:
+		if valueType != ArgumentTypeString {
+			return errors.New("path completion is only allowed for string arguments")
+		}
+	default:
+		return fmt.Errorf("invalid completion '%s', must be %s", arg.Completion, CompletionPath)
+	}
+
+	return nil
+}
+
 // validateArgumentDefault validates that a default value matches the argument type
-func validateArgumentDefault(defaultValue interface{}, argType ArgumentType) error {
+func validateArgumentDefault(arg Argument) error {
+	if arg.Type != ArgumentTypeArray {
+		return validateValue(arg.Default, arg.Type, arg.Values, "default value")
+	}
+
+	items, ok := arg.Default.([]interface{})
+	if !ok {
+		return errors.New("default value must be a list")
+	}
+	for i, item := range items {
+		if err := validateValue(item, arg.Items, arg.Values, fmt.Sprintf("default item %d", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue validates a single YAML value against a scalar argument type
+func validateValue(value interface{}, argType ArgumentType, allowed []string, label string) error {
 	switch argType {
 	case ArgumentTypeString:
-		if _, ok := defaultValue.(string); !ok {
-			return errors.New("default value must be a string")
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s must be a string", label)
 		}
 	case ArgumentTypeNumber:
-		switch defaultValue.(type) {
+		switch value.(type) {
 		case int, int64, float32, float64:
 			return nil
 		default:
-			return errors.New("default value must be a number")
+			return fmt.Errorf("%s must be a number", label)
 		}
 	case ArgumentTypeBoolean:
-		if _, ok := defaultValue.(bool); !ok {
-			return errors.New("default value must be a boolean")
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean", label)
+		}
+	case ArgumentTypeEnum:
+		str, ok := value.(string)
+		if !ok || !containsString(allowed, str) {
+			return fmt.Errorf("%s must be one of: %s", label, strings.Join(allowed, ", "))
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
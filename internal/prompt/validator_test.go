@@ -0,0 +1,214 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateEnumAndArrayArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     Argument
+		message string // empty when the argument is valid
+	}{
+		{
+			name: "valid enum",
+			arg:  Argument{Name: "language", Description: "Language", Type: ArgumentTypeEnum, Values: []string{"go", "python", "ts"}, Default: "go"},
+		},
+		{
+			name:    "enum without values",
+			arg:     Argument{Name: "language", Description: "Language", Type: ArgumentTypeEnum},
+			message: "at least one value",
+		},
+		{
+			name:    "enum duplicate value",
+			arg:     Argument{Name: "language", Description: "Language", Type: ArgumentTypeEnum, Values: []string{"go", "go"}},
+			message: "duplicate enum value",
+		},
+		{
+			name:    "enum default not allowed",
+			arg:     Argument{Name: "language", Description: "Language", Type: ArgumentTypeEnum, Values: []string{"go"}, Default: "rust"},
+			message: "must be one of: go",
+		},
+		{
+			name:    "values on string",
+			arg:     Argument{Name: "language", Description: "Language", Type: ArgumentTypeString, Values: []string{"go"}},
+			message: "only allowed for enum",
+		},
+		{
+			name: "valid array",
+			arg:  Argument{Name: "files", Description: "Files", Type: ArgumentTypeArray, Items: ArgumentTypeString, Default: []interface{}{"main.go"}},
+		},
+		{
+			name: "valid enum array",
+			arg:  Argument{Name: "langs", Description: "Languages", Type: ArgumentTypeArray, Items: ArgumentTypeEnum, Values: []string{"go", "ts"}, Default: []interface{}{"ts"}},
+		},
+		{
+			name:    "array without items",
+			arg:     Argument{Name: "files", Description: "Files", Type: ArgumentTypeArray},
+			message: "must declare an items type",
+		},
+		{
+			name:    "nested array",
+			arg:     Argument{Name: "files", Description: "Files", Type: ArgumentTypeArray, Items: ArgumentTypeArray},
+			message: "invalid items type",
+		},
+		{
+			name: "valid suggestions and path completion",
+			arg:  Argument{Name: "path", Description: "Path", Type: ArgumentTypeString, Suggestions: []string{"main.go"}, Completion: CompletionPath},
+		},
+		{
+			name:    "suggestions on enum",
+			arg:     Argument{Name: "language", Description: "Language", Type: ArgumentTypeEnum, Values: []string{"go"}, Suggestions: []string{"go"}},
+			message: "suggestions are not allowed for enum arguments",
+		},
+		{
+			name:    "number suggestion",
+			arg:     Argument{Name: "depth", Description: "Depth", Type: ArgumentTypeNumber, Suggestions: []string{"deep"}},
+			message: "suggestion 'deep' is not a valid number",
+		},
+		{
+			name:    "path completion on number",
+			arg:     Argument{Name: "counts", Description: "Counts", Type: ArgumentTypeArray, Items: ArgumentTypeNumber, Completion: CompletionPath},
+			message: "path completion is only allowed for string arguments",
+		},
+		{
+			name:    "unknown completion",
+			arg:     Argument{Name: "path", Description: "Path", Type: ArgumentTypeString, Completion: "url"},
+			message: "invalid completion 'url'",
+		},
+		{
+			name:    "items on string",
+			arg:     Argument{Name: "files", Description: "Files", Type: ArgumentTypeString, Items: ArgumentTypeString},
+			message: "only allowed for array",
+		},
+		{
+			name:    "array default not a list",
+			arg:     Argument{Name: "files", Description: "Files", Type: ArgumentTypeArray, Items: ArgumentTypeString, Default: "main.go"},
+			message: "must be a list",
+		},
+		{
+			name:    "array default item wrong type",
+			arg:     Argument{Name: "counts", Description: "Counts", Type: ArgumentTypeArray, Items: ArgumentTypeNumber, Default: []interface{}{1, "two"}},
+			message: "default item 1 must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := argumentErrors([]Argument{tt.arg})
+			if tt.message == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected valid argument, got: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.message) {
+				t.Errorf("Expected one error containing %q, got: %v", tt.message, errs)
+			}
+		})
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	base := Prompt{
+		Metadata: Metadata{
+			ID: "few-shot", Name: "Few shot", Description: "Few-shot example", Author: "test", Version: "1.0.0",
+			Created: time.Now(), Modified: time.Now(),
+		},
+		Arguments: []Argument{
+			{Name: "question", Description: "Question to answer", Type: ArgumentTypeString, Required: true},
+			{Name: "tone", Description: "Answer tone", Type: ArgumentTypeString, Required: true},
+		},
+		Messages: []Message{
+			{Role: MessageRoleSystem, Content: "Answer in a {{tone}} tone."},
+			{Role: MessageRoleUser, Content: "What is 2+2?"},
+			{Role: MessageRoleAssistant, Content: "4"},
+			{Role: MessageRoleUser, Content: "{{question}}"},
+		},
+	}
+
+	valid := base
+	if err := ValidatePrompt(&valid); err != nil {
+		t.Errorf("Expected valid messages, got: %v", err)
+	}
+
+	withResource := base
+	withResource.Arguments = append(append([]Argument{}, base.Arguments...), Argument{Name: "path", Description: "File", Type: ArgumentTypeString, Required: true})
+	withResource.Messages = append(append([]Message{}, base.Messages...), Message{Role: MessageRoleUser, Type: MessageTypeResource, URI: "file://{{path}}"})
+	if err := ValidatePrompt(&withResource); err != nil {
+		t.Errorf("Expected valid resource message, got: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *Prompt)
+		message string
+	}{
+		{"both prompt and messages", func(p *Prompt) { p.Prompt = "Hi" }, "prompt and messages cannot both be set"},
+		{"invalid role", func(p *Prompt) { p.Messages[1].Role = "tool" }, "message 1: invalid role 'tool'"},
+		{"empty content", func(p *Prompt) { p.Messages[2].Content = " " }, "message 2 (assistant): content is required"},
+		{"undefined variable", func(p *Prompt) { p.Messages[2].Content = "{{answer}}" }, "message 2 (assistant): undefined variable 'answer'"},
+		{"required argument unused", func(p *Prompt) { p.Messages[0].Content = "Be nice." }, "required argument 'tone' is not used in any message"},
+		{"invalid type", func(p *Prompt) { p.Messages[1].Type = "image" }, "message 1 (user): invalid type 'image'"},
+		{"resource without uri", func(p *Prompt) { p.Messages[2] = Message{Role: MessageRoleUser, Type: MessageTypeResource} }, "uri is required for resource messages"},
+		{"resource with content", func(p *Prompt) { p.Messages[2].Type = MessageTypeResource; p.Messages[2].URI = "file://a" }, "content is not allowed for resource messages"},
+		{"resource scheme", func(p *Prompt) {
+			p.Messages[2] = Message{Role: MessageRoleUser, Type: MessageTypeResource, URI: "http://x"}
+		}, "uri must start with one of: file://, prompt://"},
+		{"resource undefined variable", func(p *Prompt) {
+			p.Messages[2] = Message{Role: MessageRoleUser, Type: MessageTypeResource, URI: "file://{{path}}"}
+		}, "undefined variable 'path'"},
+		{"text with uri", func(p *Prompt) { p.Messages[2].URI = "file://a" }, "uri is only allowed for resource messages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			p.Messages = append([]Message{}, base.Messages...)
+			tt.modify(&p)
+
+			err := ValidatePrompt(&p)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestValidatePromptAll(t *testing.T) {
+	p := Prompt{
+		Metadata: Metadata{ID: "bad id", Name: "Bad", Version: "1.0.0", Created: time.Now(), Modified: time.Now()},
+		Arguments: []Argument{
+			{Name: "first", Type: ArgumentTypeString},
+			{Name: "second", Description: "Second", Type: "color"},
+			{Name: "third", Description: "Third", Type: ArgumentTypeString},
+		},
+		Prompt: "{{first}} {{missing}}",
+	}
+
+	expected := []string{
+		"metadata validation failed: id must contain only alphanumeric characters",
+		"metadata validation failed: description is required",
+		"metadata validation failed: author is required",
+		"arguments validation failed: argument 0 (first): description is required",
+		"arguments validation failed: argument 1 (second): invalid type 'color'",
+		"prompt content validation failed: undefined variable 'missing'",
+	}
+
+	errs := ValidatePromptAll(&p)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, message := range expected {
+		if !strings.Contains(errs[i].Error(), message) {
+			t.Errorf("Error %d: expected %q, got %q", i, message, errs[i])
+		}
+	}
+
+	// ValidatePrompt reports the first of them
+	if err := ValidatePrompt(&p); err == nil || err.Error() != errs[0].Error() {
+		t.Errorf("Expected first error %q, got %v", errs[0], err)
+	}
+}
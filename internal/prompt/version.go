@@ -0,0 +1,235 @@
+package prompt
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverPattern matches a semantic version as defined at https://semver.org:
+// MAJOR.MINOR.PATCH without leading zeros, with an optional pre-release
+// after "-" and build metadata after "+"
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Version is a parsed semantic version
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // dot-separated identifiers after "-", empty for a release
+	Build      string // build metadata after "+", ignored in comparisons
+}
+
+// ParseVersion parses a semantic version such as "1.4.2" or "2.0.0-rc.1"
+func ParseVersion(s string) (Version, error) {
+	match := semverPattern.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("version must be a semantic version (MAJOR.MINOR.PATCH), got '%s'", s)
+	}
+
+	var v Version
+	var err error
+	for i, target := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if *target, err = strconv.Atoi(match[i+1]); err != nil {
+			return Version{}, fmt.Errorf("version '%s' is out of range", s)
+		}
+	}
+	v.Prerelease, v.Build = match[4], match[5]
+	return v, nil
+}
+
+// String formats the version
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 as v has lower, equal or higher precedence
+// than other. A pre-release has lower precedence than its release.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+
+	left, right := strings.Split(v.Prerelease, "."), strings.Split(other.Prerelease, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if c := comparePrereleaseIdentifiers(left[i], right[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(left), len(right))
+}
+
+// comparePrereleaseIdentifiers compares pre-release identifiers: numeric
+// identifiers numerically and below alphanumeric ones, which compare as text
+func comparePrereleaseIdentifiers(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareInts returns -1, 0 or 1 as a is less than, equal to or greater than b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CheckVersionChange checks that the version of next, a new definition of
+// the prompt old, was bumped as its changes require: any change to the body
+// or arguments needs a higher version, and a breaking argument change needs
+// a higher major version. Definitions are compared as written, before
+// partials and inheritance are resolved. Nothing is required if the old
+// version is not a semantic version.
+func CheckVersionChange(old, next *Prompt) error {
+	oldVersion, err := ParseVersion(old.Metadata.Version)
+	if err != nil {
+		return nil
+	}
+	nextVersion, err := ParseVersion(next.Metadata.Version)
+	if err != nil {
+		return err
+	}
+
+	if nextVersion.Compare(oldVersion) < 0 {
+		return fmt.Errorf("version %s is lower than the previous version %s", nextVersion, oldVersion)
+	}
+
+	if breaking := breakingArgumentChanges(old.Arguments, next.Arguments); len(breaking) > 0 && nextVersion.Major <= oldVersion.Major {
+		return fmt.Errorf("breaking argument change requires a major version bump from %s, got %s: %s",
+			oldVersion, nextVersion, strings.Join(breaking, ", "))
+	}
+
+	if definitionChanged(old, next) && nextVersion.Compare(oldVersion) == 0 {
+		return fmt.Errorf("prompt body or arguments changed without a version bump from %s", oldVersion)
+	}
+
+	return nil
+}
+
+// breakingArgumentChanges describes the argument changes that break
+// existing callers: removed arguments, new required arguments, and
+// arguments that became required or changed type
+func breakingArgumentChanges(old, next []Argument) []string {
+	oldArgs := make(map[string]Argument, len(old))
+	for _, arg := range old {
+		oldArgs[arg.Name] = arg
+	}
+	nextArgs := make(map[string]bool, len(next))
+
+	var changes []string
+	for _, arg := range next {
+		nextArgs[arg.Name] = true
+		previous, exists := oldArgs[arg.Name]
+		switch {
+		case !exists && arg.Required:
+			changes = append(changes, fmt.Sprintf("new required argument '%s'", arg.Name))
+		case !exists:
+		case arg.Required && !previous.Required:
+			changes = append(changes, fmt.Sprintf("argument '%s' became required", arg.Name))
+		case arg.Type != previous.Type:
+			changes = append(changes, fmt.Sprintf("argument '%s' changed type from %s to %s", arg.Name, previous.Type, arg.Type))
+		}
+	}
+
+	for _, arg := range old {
+		if !nextArgs[arg.Name] {
+			changes = append(changes, fmt.Sprintf("argument '%s' was removed", arg.Name))
+		}
+	}
+	return changes
+}
+
+// definitionChanged reports whether the body or arguments of a prompt
+// changed. Metadata, usage statistics, argument descriptions and completion
+// hints are ignored, as is how a definition is written: an explicit
+// "required: false" or an empty list or map equals a missing one.
+func definitionChanged(old, next *Prompt) bool {
+	type definition struct {
+		Extends   string
+		Arguments []argumentDefinition
+		Prompt    string
+		Messages  []Message
+		Blocks    map[string]string
+	}
+	return !reflect.DeepEqual(
+		definition{old.Extends, argumentDefinitions(old.Arguments), old.Prompt, emptyAsNil(old.Messages), emptyMapAsNil(old.Blocks)},
+		definition{next.Extends, argumentDefinitions(next.Arguments), next.Prompt, emptyAsNil(next.Messages), emptyMapAsNil(next.Blocks)},
+	)
+}
+
+// argumentDefinition holds the fields of an argument that affect how a
+// prompt is called and rendered
+type argumentDefinition struct {
+	Name     string
+	Type     ArgumentType
+	Required bool
+	Default  interface{}
+	Values   []string
+	Items    ArgumentType
+}
+
+// argumentDefinitions returns the effective definitions of arguments
+func argumentDefinitions(arguments []Argument) []argumentDefinition {
+	if len(arguments) == 0 {
+		return nil
+	}
+	definitions := make([]argumentDefinition, len(arguments))
+	for i, arg := range arguments {
+		definitions[i] = argumentDefinition{arg.Name, arg.Type, arg.Required, arg.Default, emptyAsNil(arg.Values), arg.Items}
+	}
+	return definitions
+}
+
+// emptyAsNil returns nil for an empty slice, so that an empty list and a
+// missing one compare equal
+func emptyAsNil[T any](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+// emptyMapAsNil returns nil for an empty map, so that an empty map and a
+// missing one compare equal
+func emptyMapAsNil[K comparable, V any](m map[K]V) map[K]V {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,126 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	valid := map[string]Version{
+		"1.0.0":                {Major: 1},
+		"0.12.3":               {Minor: 12, Patch: 3},
+		"2.0.0-rc.1":           {Major: 2, Prerelease: "rc.1"},
+		"1.4.2-beta+build.5":   {Major: 1, Minor: 4, Patch: 2, Prerelease: "beta", Build: "build.5"},
+		"10.20.30+20250827abc": {Major: 10, Minor: 20, Patch: 30, Build: "20250827abc"},
+	}
+	for s, expected := range valid {
+		v, err := ParseVersion(s)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", s, err)
+			continue
+		}
+		if v != expected || v.String() != s {
+			t.Errorf("Expected %q to parse as %+v, got %+v (%s)", s, expected, v, v)
+		}
+	}
+
+	for _, s := range []string{"1.0", "v1.0.0", "01.0.0", "1.0.0-", "1.0.0-01", "1.0.0+", "latest", "1.0.0 "} {
+		if _, err := ParseVersion(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+
+	p := Prompt{
+		Metadata: Metadata{ID: "p", Name: "P", Description: "D", Author: "a", Version: "1.0", Created: time.Now(), Modified: time.Now()},
+		Prompt:   "Hello",
+	}
+	if err := ValidatePrompt(&p); err == nil || !strings.Contains(err.Error(), "version must be a semantic version (MAJOR.MINOR.PATCH), got '1.0'") {
+		t.Errorf("Expected invalid version to fail validation, got %v", err)
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// Each version has lower precedence than the next, as in the semver spec
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := 0; i+1 < len(ordered); i++ {
+		a, _ := ParseVersion(ordered[i])
+		b, _ := ParseVersion(ordered[i+1])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("Expected %s < %s", a, b)
+		}
+	}
+
+	a, _ := ParseVersion("1.0.0+one")
+	b, _ := ParseVersion("1.0.0+two")
+	if a.Compare(b) != 0 {
+		t.Error("Expected build metadata to be ignored")
+	}
+}
+
+func TestCheckVersionChange(t *testing.T) {
+	old := Prompt{
+		Metadata: Metadata{ID: "review", Version: "1.2.0"},
+		Arguments: []Argument{
+			{Name: "code", Type: ArgumentTypeString, Required: true},
+			{Name: "focus", Type: ArgumentTypeString},
+		},
+		Prompt: "Review {{code}}",
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		modify   func(p *Prompt)
+		expected string // empty if the change is allowed
+	}{
+		{"unchanged", "1.2.0", func(p *Prompt) {}, ""},
+		{"metadata only", "1.2.0", func(p *Prompt) { p.Metadata.Description = "New" }, ""},
+		{"explicit required false", "1.2.0", func(p *Prompt) { p.Arguments[1].requiredSet = true }, ""},
+		{"empty blocks", "1.2.0", func(p *Prompt) { p.Blocks = map[string]string{} }, ""},
+		{"argument description", "1.2.0", func(p *Prompt) { p.Arguments[1].Description = "What to focus on" }, ""},
+		{"argument default without bump", "1.2.0", func(p *Prompt) { p.Arguments[1].Default = "security" }, "changed without a version bump"},
+		{"body without bump", "1.2.0", func(p *Prompt) { p.Prompt = "Review {{code}} carefully" }, "changed without a version bump from 1.2.0"},
+		{"body with patch bump", "1.2.1", func(p *Prompt) { p.Prompt = "Review {{code}} carefully" }, ""},
+		{"optional argument with minor bump", "1.3.0", func(p *Prompt) {
+			p.Arguments = append(p.Arguments, Argument{Name: "style", Type: ArgumentTypeString})
+		}, ""},
+		{"optional argument without bump", "1.2.0", func(p *Prompt) {
+			p.Arguments = append(p.Arguments, Argument{Name: "style", Type: ArgumentTypeString})
+		}, "changed without a version bump"},
+		{"new required argument", "1.3.0", func(p *Prompt) {
+			p.Arguments = append(p.Arguments, Argument{Name: "style", Type: ArgumentTypeString, Required: true})
+		}, "requires a major version bump from 1.2.0, got 1.3.0: new required argument 'style'"},
+		{"removed argument", "1.2.1", func(p *Prompt) { p.Arguments = p.Arguments[:1] }, "argument 'focus' was removed"},
+		{"argument became required", "1.3.0", func(p *Prompt) { p.Arguments[1].Required = true }, "argument 'focus' became required"},
+		{"argument type", "1.3.0", func(p *Prompt) { p.Arguments[1].Type = ArgumentTypeArray }, "argument 'focus' changed type from string to array"},
+		{"breaking with major bump", "2.0.0", func(p *Prompt) { p.Arguments = p.Arguments[:1] }, ""},
+		{"version lowered", "1.1.0", func(p *Prompt) {}, "version 1.1.0 is lower than the previous version 1.2.0"},
+		{"invalid version", "2", func(p *Prompt) {}, "must be a semantic version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := old
+			next.Arguments = append([]Argument{}, old.Arguments...)
+			next.Metadata.Version = tt.version
+			tt.modify(&next)
+
+			err := CheckVersionChange(&old, &next)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected change to be allowed, got %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// Prompts from before versions were checked cannot be compared
+	legacy := old
+	legacy.Metadata.Version = "draft"
+	if err := CheckVersionChange(&legacy, &old); err != nil {
+		t.Errorf("Expected no check against a non-semver version, got %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+// Package search provides an in-memory full-text index over a prompt
+// library. Queries are ranked, tolerate typos and may restrict terms to a
+// single field, as in "review tag:testing author:team".
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// Searchable fields, as used in field-qualified query terms
+const (
+	FieldID          = "id"
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldTag         = "tag"
+	FieldAuthor      = "author"
+	FieldCategory    = "category"
+	FieldBody        = "body"
+)
+
+// fieldWeights is the score a full match in each field contributes. Free
+// text terms are matched against every field.
+var fieldWeights = map[string]float64{
+	FieldID:          3,
+	FieldName:        3,
+	FieldTag:         2.5,
+	FieldDescription: 2,
+	FieldCategory:    1,
+	FieldAuthor:      1,
+	FieldBody:        1,
+}
+
+// sortedFields lists the searchable fields in a fixed order
+var sortedFields = []string{FieldID, FieldName, FieldTag, FieldDescription, FieldCategory, FieldAuthor, FieldBody}
+
+// fieldAliases maps alternative field names in queries to fields
+var fieldAliases = map[string]string{
+	"tags":   FieldTag,
+	"desc":   FieldDescription,
+	"prompt": FieldBody,
+}
+
+// Match qualities of a query term against a token
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.75
+	fuzzyMatch  = 0.5 // for one edit; halved for each further edit
+)
+
+// Result is a prompt matching a query
+type Result struct {
+	Prompt *prompt.Prompt
+	Score  float64
+}
+
+// Index is an immutable search index over a set of prompts
+type Index struct {
+	documents []document
+}
+
+// document holds the tokens of each field of a prompt
+type document struct {
+	prompt *prompt.Prompt
+	fields map[string][]string
+}
+
+// term is a single query term, optionally restricted to a field
+type term struct {
+	field string // empty for free text
+	text  string
+}
+
+// NewIndex indexes the prompts of a library. categoryOf returns the category
+// of a prompt file and may be nil if categories are not searchable.
+func NewIndex(library *prompt.PromptLibrary, categoryOf func(filePath string) string) *Index {
+	index := &Index{}
+	if library == nil {
+		return index
+	}
+
+	for _, p := range library.ListPrompts() {
+		fields := map[string][]string{
+			FieldID:          tokenize(p.Metadata.ID),
+			FieldName:        tokenize(p.Metadata.Name),
+			FieldDescription: tokenize(p.Metadata.Description),
+			FieldTag:         tokenize(strings.Join(p.Metadata.Tags, " ")),
+			FieldAuthor:      tokenize(p.Metadata.Author),
+			FieldBody:        tokenize(promptBody(p)),
+		}
+		if categoryOf != nil {
+			fields[FieldCategory] = tokenize(categoryOf(p.FilePath))
+		}
+		for field, tokens := range fields {
+			fields[field] = unique(tokens)
+		}
+		index.documents = append(index.documents, document{prompt: p, fields: fields})
+	}
+
+	// Keep the order of equally ranked results stable
+	sort.Slice(index.documents, func(i, j int) bool {
+		return index.documents[i].prompt.Metadata.ID < index.documents[j].prompt.Metadata.ID
+	})
+
+	return index
+}
+
+// Len returns the number of indexed prompts
+func (idx *Index) Len() int {
+	return len(idx.documents)
+}
+
+// Search returns the prompts matching every term of query, best match
+// first. An empty query matches every prompt. limit caps the number of
+// results; zero or less means no limit.
+func (idx *Index) Search(query string, limit int) ([]Result, error) {
+	terms, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, doc := range idx.documents {
+		score, matched := doc.score(terms)
+		if matched {
+			results = append(results, Result{Prompt: doc.prompt, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// score scores a document against all terms. Every term must match.
+func (doc document) score(terms []term) (float64, bool) {
+	total := 0.0
+	for _, t := range terms {
+		fields := []string{t.field}
+		if t.field == "" {
+			fields = sortedFields
+		}
+
+		termScore := 0.0
+		for _, field := range fields {
+			termScore += fieldWeights[field] * bestMatch(t.text, doc.fields[field])
+		}
+		if termScore == 0 {
+			return 0, false
+		}
+		total += termScore
+	}
+	return total, true
+}
+
+// bestMatch returns the quality of the best match of text among tokens
+func bestMatch(text string, tokens []string) float64 {
+	best := 0.0
+	for _, token := range tokens {
+		quality := matchQuality(text, token)
+		if quality > best {
+			best = quality
+			if best == exactMatch {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// matchQuality scores how well a query word matches an indexed token
+func matchQuality(text, token string) float64 {
+	if text == token {
+		return exactMatch
+	}
+	if len(text) >= 2 && strings.HasPrefix(token, text) {
+		return prefixMatch
+	}
+
+	allowed := maxEdits(text)
+	if allowed == 0 {
+		return 0
+	}
+	distance := editDistance(text, token, allowed)
+	if distance > allowed {
+		return 0
+	}
+	quality := fuzzyMatch
+	for i := 1; i < distance; i++ {
+		quality /= 2
+	}
+	return quality
+}
+
+// maxEdits returns how many typos are tolerated in a query word
+func maxEdits(text string) int {
+	length := len([]rune(text))
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b, or
+// limit+1 once the distance is known to exceed limit
+func editDistance(a, b string, limit int) int {
+	ar, br := []rune(a), []rune(b)
+	if abs(len(ar)-len(br)) > limit {
+		return limit + 1
+	}
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		previous, current = current, previous
+	}
+	return previous[len(br)]
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// parseQuery splits a query into terms. Words are separated by whitespace;
+// double quotes group words, so author:"platform team" restricts both words
+// to the author field.
+func parseQuery(query string) ([]term, error) {
+	var terms []term
+	for _, word := range splitQuery(query) {
+		field := ""
+		if name, value, found := strings.Cut(word, ":"); found && isFieldName(name) {
+			var err error
+			if field, err = lookupField(name); err != nil {
+				return nil, err
+			}
+			word = value
+		}
+
+		for _, text := range tokenize(word) {
+			terms = append(terms, term{field: field, text: text})
+		}
+	}
+	return terms, nil
+}
+
+// splitQuery splits a query at whitespace outside double quotes and removes
+// the quotes
+func splitQuery(query string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(r)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// isFieldName reports whether name looks like a field qualifier rather than
+// text that happens to contain a colon
+func isFieldName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField resolves a field name used in a query
+func lookupField(name string) (string, error) {
+	name = strings.ToLower(name)
+	if alias, exists := fieldAliases[name]; exists {
+		name = alias
+	}
+	if _, exists := fieldWeights[name]; !exists {
+		return "", fmt.Errorf("unknown search field '%s', must be one of: %s", name, strings.Join(sortedFields, ", "))
+	}
+	return name, nil
+}
+
+// tokenize lower-cases text and splits it into words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// unique returns tokens without duplicates, keeping the first occurrence
+func unique(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	result := tokens[:0]
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// promptBody returns the template text of a prompt, including every message
+func promptBody(p *prompt.Prompt) string {
+	parts := []string{p.Prompt}
+	for _, message := range p.Messages {
+		parts = append(parts, message.Content)
+	}
+	return strings.Join(parts, "\n")
+}
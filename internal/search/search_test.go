@@ -0,0 +1,136 @@
+package search
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// testLibrary returns a small library with prompts in three categories
+func testLibrary() *prompt.PromptLibrary {
+	library := prompt.NewPromptLibrary()
+	library.AddPrompt(&prompt.Prompt{
+		Metadata: prompt.Metadata{
+			ID: "code-review", Name: "Code Review Assistant", Description: "Reviews code for quality",
+			Author: "Platform Team", Tags: []string{"review", "quality"},
+		},
+		Prompt:   "Review the following {{language}} code for security issues.",
+		FilePath: filepath.Join("prompts", "development", "code-review.yaml"),
+	})
+	library.AddPrompt(&prompt.Prompt{
+		Metadata: prompt.Metadata{
+			ID: "test-gen", Name: "Test Generator", Description: "Generates unit tests",
+			Author: "qa", Tags: []string{"testing"},
+		},
+		Messages: []prompt.Message{
+			{Role: prompt.MessageRoleSystem, Content: "You write table driven tests."},
+			{Role: prompt.MessageRoleUser, Content: "Write tests for {{code}}, including a security review."},
+		},
+		FilePath: filepath.Join("prompts", "testing", "test-gen.yaml"),
+	})
+	library.AddPrompt(&prompt.Prompt{
+		Metadata: prompt.Metadata{
+			ID: "api-docs", Name: "API Documentation", Description: "Documents an API",
+			Author: "docs team", Tags: []string{"documentation"},
+		},
+		Prompt:   "Document the {{api}} endpoints.",
+		FilePath: filepath.Join("prompts", "documentation", "api-docs.yaml"),
+	})
+	return library
+}
+
+// categoryOf returns the directory of a prompt file
+func categoryOf(filePath string) string {
+	return filepath.Base(filepath.Dir(filePath))
+}
+
+func TestSearch(t *testing.T) {
+	index := NewIndex(testLibrary(), categoryOf)
+	if index.Len() != 3 {
+		t.Fatalf("Expected 3 indexed prompts, got %d", index.Len())
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string // IDs in rank order
+	}{
+		{"empty query", "", []string{"api-docs", "code-review", "test-gen"}},
+		{"name beats body", "review", []string{"code-review", "test-gen"}},
+		{"prefix", "doc", []string{"api-docs"}},
+		{"typo", "securty", []string{"code-review", "test-gen"}},
+		{"two typos in long word", "documantaton", []string{"api-docs"}},
+		{"short words need exact match", "ap", []string{"api-docs"}},
+		{"all terms must match", "review table", []string{"test-gen"}},
+		{"message content", "table", []string{"test-gen"}},
+		{"tag field", "tag:testing", []string{"test-gen"}},
+		{"author field", "author:team", []string{"api-docs", "code-review"}},
+		{"quoted field value", `author:"platform team"`, []string{"code-review"}},
+		{"category field", "category:development", []string{"code-review"}},
+		{"field alias", "tags:quality", []string{"code-review"}},
+		{"field and text", "security author:qa", []string{"test-gen"}},
+		{"field restricts match", "name:security", []string{}},
+		{"no match", "kubernetes", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := index.Search(tt.query, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			ids := []string{}
+			for _, result := range results {
+				ids = append(ids, result.Prompt.Metadata.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSearchLimitAndErrors(t *testing.T) {
+	index := NewIndex(testLibrary(), nil)
+
+	results, err := index.Search("", 2)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Expected 2 results, got %d (%v)", len(results), err)
+	}
+
+	if _, err := index.Search("colour:red", 0); err == nil || !strings.Contains(err.Error(), "unknown search field 'colour'") {
+		t.Errorf("Expected unknown field error, got %v", err)
+	}
+
+	// Without a category function categories cannot be searched
+	if results, _ := index.Search("category:testing", 0); len(results) != 0 {
+		t.Errorf("Expected no category matches, got %d", len(results))
+	}
+
+	if empty := NewIndex(nil, nil); empty.Len() != 0 {
+		t.Errorf("Expected empty index, got %d prompts", empty.Len())
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		limit    int
+		expected int
+	}{
+		{"review", "review", 2, 0},
+		{"reveiw", "review", 2, 2},
+		{"securty", "security", 1, 1},
+		{"test", "documentation", 2, 3},
+		{"kitten", "sitting", 3, 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.limit); got != tt.expected {
+			t.Errorf("editDistance(%q, %q, %d): expected %d, got %d", tt.a, tt.b, tt.limit, tt.expected, got)
+		}
+	}
+}
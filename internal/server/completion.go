@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// maxCompletionValues is the most values a completion response may carry
+const maxCompletionValues = 100
+
+// CompletePromptArgument implements server.PromptCompletionProvider. It
+// completes argument values from the allowed values of enum arguments, from
+// defaults and suggestions, and from file paths for arguments that ask for
+// path completion.
+func (s *Server) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	arg, exists := s.findArgument(promptName, argument.Name)
+	if !exists {
+		return &mcp.Completion{Values: []string{}}, nil
+	}
+
+	// Array values are completed one comma-separated item at a time
+	prefix, current := "", argument.Value
+	if arg.Type == prompt.ArgumentTypeArray {
+		if i := strings.LastIndex(current, ","); i >= 0 {
+			prefix, current = current[:i+1]+" ", strings.TrimSpace(current[i+1:])
+		}
+	}
+
+	candidates := argumentCandidates(arg)
+	if arg.Completion == prompt.CompletionPath {
+		candidates = append(candidates, s.completePath(current)...)
+	}
+
+	var values []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		if seen[candidate] || !strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(current)) {
+			continue
+		}
+		seen[candidate] = true
+		values = append(values, prefix+candidate)
+	}
+
+	return newCompletion(values), nil
+}
+
+// argumentCandidates returns the values an argument is completed from: its
+// allowed values, then its default, then its suggestions
+func argumentCandidates(arg prompt.Argument) []string {
+	candidates := append([]string{}, arg.AllowedValues()...)
+
+	switch value := arg.Default.(type) {
+	case nil:
+	case []interface{}:
+		for _, item := range value {
+			candidates = append(candidates, fmt.Sprint(item))
+		}
+	default:
+		candidates = append(candidates, fmt.Sprint(value))
+	}
+
+	return append(candidates, arg.Suggestions...)
+}
+
+// completePath lists the files and directories below the embed root that
+// start with current. Directories end with a slash so that completion can
+// continue into them. Hidden entries are only listed once a dot is typed.
+func (s *Server) completePath(current string) []string {
+	if s.config.EmbedRoot == "" {
+		return nil
+	}
+
+	dir, base := path.Split(current)
+	resolved, err := s.resolveEmbedPath(dir)
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir(resolved)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		paths = append(paths, dir+name)
+	}
+	return paths
+}
+
+// findArgument looks up an argument of a prompt in the current library
+func (s *Server) findArgument(promptName, argumentName string) (prompt.Argument, bool) {
+	library := s.GetLibrary()
+	if library == nil {
+		return prompt.Argument{}, false
+	}
+
+	p, exists := library.GetPrompt(promptName)
+	if !exists {
+		return prompt.Argument{}, false
+	}
+
+	for _, arg := range p.Arguments {
+		if arg.Name == argumentName {
+			return arg, true
+		}
+	}
+	return prompt.Argument{}, false
+}
+
+// newCompletion builds a completion result, truncated to the protocol limit
+func newCompletion(values []string) *mcp.Completion {
+	completion := &mcp.Completion{Values: values, Total: len(values)}
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+
+	if len(values) > maxCompletionValues {
+		completion.Values = values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return completion
+}
+
+// argumentDescription describes an argument for MCP clients, including the
+// allowed values of enum arguments and the item type of arrays
+func argumentDescription(arg prompt.Argument) string {
+	description := arg.Description
+
+	switch arg.Type {
+	case prompt.ArgumentTypeEnum:
+		description += " (one of: " + strings.Join(arg.Values, ", ") + ")"
+
+	case prompt.ArgumentTypeArray:
+		items := string(arg.Items)
+		if arg.Items == prompt.ArgumentTypeEnum {
+			items = strings.Join(arg.Values, ", ")
+		}
+		description += " (list of " + items + "; JSON array or comma-separated)"
+	}
+
+	return description
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const completionPrompt = `metadata:
+  id: "analyze"
+  name: "Analyze"
+  description: "Analyzes code"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "language"
+    description: "Language of the code"
+    type: "enum"
+    values: ["go", "python", "typescript"]
+  - name: "focus_area"
+    description: "What to focus on"
+    type: "string"
+    default: "performance"
+    suggestions: ["performance", "security", "readability"]
+  - name: "depth"
+    description: "Analysis depth"
+    type: "number"
+    suggestions: ["1", "2", "3"]
+  - name: "path"
+    description: "File to analyze"
+    type: "string"
+    completion: "path"
+  - name: "files"
+    description: "Files to analyze"
+    type: "array"
+    items: "string"
+    completion: "path"
+
+prompt: |
+  Analyze {{path}} {{files}} in {{language}} for {{focus_area}} at depth {{depth}}.
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+func TestCompletePromptArgument(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := os.WriteFile(filepath.Join(dir, "analyze.yaml"), []byte(completionPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	root := t.TempDir()
+	writeEmbedFile(t, root, "src/main.go", []byte("package main\n"))
+	writeEmbedFile(t, root, "src/server.go", []byte("package main\n"))
+	writeEmbedFile(t, root, "README.md", []byte("# readme\n"))
+	writeEmbedFile(t, root, ".env", []byte("SECRET=1\n"))
+	srv.config.EmbedRoot = root
+
+	tests := []struct {
+		name     string
+		argument string
+		value    string
+		expected []string
+	}{
+		{"enum values", "language", "", []string{"go", "python", "typescript"}},
+		{"enum prefix", "language", "Py", []string{"python"}},
+		{"default before suggestions", "focus_area", "", []string{"performance", "security", "readability"}},
+		{"suggestion prefix", "focus_area", "se", []string{"security"}},
+		{"number suggestions", "depth", "2", []string{"2"}},
+		{"root entries", "path", "", []string{"README.md", "src/"}},
+		{"hidden entries on dot", "path", ".", []string{".env"}},
+		{"nested entries", "path", "src/s", []string{"src/server.go"}},
+		{"array item paths", "files", "README.md, src/m", []string{"README.md, src/main.go"}},
+		{"outside root", "path", "../", []string{}},
+		{"unknown argument", "missing", "", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argument := mcp.CompleteArgument{Name: tt.argument, Value: tt.value}
+			completion, err := srv.CompletePromptArgument(context.Background(), "analyze", argument, mcp.CompleteContext{})
+			if err != nil {
+				t.Fatalf("Completion failed: %v", err)
+			}
+			if !reflect.DeepEqual(completion.Values, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, completion.Values)
+			}
+		})
+	}
+
+	srv.config.EmbedRoot = ""
+	argument := mcp.CompleteArgument{Name: "path", Value: ""}
+	completion, err := srv.CompletePromptArgument(context.Background(), "analyze", argument, mcp.CompleteContext{})
+	if err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+	if len(completion.Values) != 0 {
+		t.Errorf("Expected no path completions without an embed root, got %v", completion.Values)
+	}
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// loadReport summarises the last successful load of the prompt library
+type loadReport struct {
+	Mode        prompt.LoadMode     `json:"mode"`
+	Generation  uint64              `json:"generation"`
+	Loaded      int                 `json:"loaded"`
+	Skipped     int                 `json:"skipped"`
+	Diagnostics []prompt.Diagnostic `json:"diagnostics"`
+}
+
+// loadReport describes the current library and the prompt files skipped
+// while loading it. Only lenient loading skips files.
+func (s *Server) loadReport() loadReport {
+	report := loadReport{
+		Mode:        s.config.LoadMode,
+		Diagnostics: []prompt.Diagnostic{},
+	}
+	if report.Mode == "" {
+		report.Mode = prompt.LoadStrict
+	}
+
+	library := s.GetLibrary()
+	if library == nil {
+		return report
+	}
+
+	report.Generation = library.Generation
+	report.Loaded = library.Len()
+	report.Skipped = len(library.Diagnostics)
+	if library.Diagnostics != nil {
+		report.Diagnostics = library.Diagnostics
+	}
+	return report
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// DefaultEmbedMaxBytes is the largest file a resource message may embed
+// when no limit is configured
+const DefaultEmbedMaxBytes = 1 << 20
+
+// URI schemes of the resources a message may embed
+const (
+	fileScheme   = "file://"
+	promptScheme = "prompt://"
+)
+
+// embedResource loads the resource a rendered resource message refers to
+func (s *Server) embedResource(ctx context.Context, uri string) (mcp.ResourceContents, error) {
+	switch {
+	case strings.HasPrefix(uri, fileScheme):
+		return s.embedFile(uri)
+	case strings.HasPrefix(uri, promptScheme):
+		return s.embedPromptResource(ctx, uri)
+	default:
+		return nil, fmt.Errorf("cannot embed '%s': unsupported URI scheme", uri)
+	}
+}
+
+// embedFile reads a file:// URI below the embed root. Relative paths are
+// resolved against the root.
+func (s *Server) embedFile(uri string) (mcp.ResourceContents, error) {
+	path, err := s.resolveEmbedPath(strings.TrimPrefix(uri, fileScheme))
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed '%s': %w", uri, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed '%s': %w", uri, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed '%s': %w", uri, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("cannot embed '%s': not a regular file", uri)
+	}
+
+	limit := s.config.EmbedMaxBytes
+	if limit <= 0 {
+		limit = DefaultEmbedMaxBytes
+	}
+	if info.Size() > limit {
+		return nil, fmt.Errorf("cannot embed '%s': file is %d bytes, limit is %d", uri, info.Size(), limit)
+	}
+
+	// The file may grow after the size check, so never read past the limit
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed '%s': %w", uri, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("cannot embed '%s': file is larger than the limit of %d bytes", uri, limit)
+	}
+
+	return newResourceContents(uri, detectMIMEType(path, data), data), nil
+}
+
+// resolveEmbedPath returns the real path of name and checks that it lies
+// below the embed root, following symbolic links
+func (s *Server) resolveEmbedPath(name string) (string, error) {
+	if s.config.EmbedRoot == "" {
+		return "", errors.New("file embedding is disabled, set embed.root to enable it")
+	}
+
+	root, err := filepath.EvalSymlinks(s.config.EmbedRoot)
+	if err != nil {
+		return "", fmt.Errorf("invalid embed root: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid embed root: %w", err)
+	}
+
+	path := filepath.FromSlash(name)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("path is outside the embed root")
+	}
+
+	return path, nil
+}
+
+// embedPromptResource reads one of the prompt:// resources of this server
+func (s *Server) embedPromptResource(ctx context.Context, uri string) (mcp.ResourceContents, error) {
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+
+	read := s.handlePromptResource
+	if strings.HasSuffix(uri, renderedSuffix) {
+		read = s.handleRenderedResource
+	}
+
+	contents, err := read(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed '%s': %w", uri, err)
+	}
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("cannot embed '%s': resource is empty", uri)
+	}
+	return contents[0], nil
+}
+
+// detectMIMEType guesses the MIME type of a file from its extension and,
+// failing that, from its content
+func detectMIMEType(path string, data []byte) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+		return mimeType
+	}
+
+	mimeType := http.DetectContentType(data)
+	if mimeType == "application/octet-stream" && utf8.Valid(data) {
+		return "text/plain; charset=utf-8"
+	}
+	return mimeType
+}
+
+// newResourceContents returns text contents for textual data and base64
+// encoded blob contents otherwise
+func newResourceContents(uri, mimeType string, data []byte) mcp.ResourceContents {
+	if isTextMIMEType(mimeType) && utf8.Valid(data) {
+		return mcp.TextResourceContents{URI: uri, MIMEType: mimeType, Text: string(data)}
+	}
+	return mcp.BlobResourceContents{URI: uri, MIMEType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}
+}
+
+// isTextMIMEType reports whether a MIME type describes text
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/javascript", "application/x-sh", "application/toml":
+		return true
+	}
+	return false
+}
+
+// newPromptMessage converts a rendered message into an MCP prompt message,
+// embedding the referenced resource for resource messages
+func (s *Server) newPromptMessage(ctx context.Context, message prompt.Message) (mcp.PromptMessage, error) {
+	if !message.IsResource() {
+		return mcp.NewPromptMessage(mcpRole(message.Role), mcp.NewTextContent(message.Content)), nil
+	}
+
+	contents, err := s.embedResource(ctx, message.URI)
+	if err != nil {
+		return mcp.PromptMessage{}, err
+	}
+	return mcp.NewPromptMessage(mcpRole(message.Role), mcp.NewEmbeddedResource(contents)), nil
+}
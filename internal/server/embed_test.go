@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const embedPrompt = `metadata:
+  id: "review-file"
+  name: "Review File"
+  description: "Reviews a file"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "path"
+    description: "File to review"
+    type: "string"
+    required: true
+
+messages:
+  - role: "user"
+    content: "Please review this file:"
+  - role: "user"
+    type: "resource"
+    uri: "file://{{path}}"
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+// embedResult is the shape of a prompts/get result with embedded resources
+type embedResult struct {
+	Messages []struct {
+		Role    string `json:"role"`
+		Content struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Resource struct {
+				URI      string `json:"uri"`
+				MIMEType string `json:"mimeType"`
+				Text     string `json:"text"`
+				Blob     string `json:"blob"`
+			} `json:"resource"`
+		} `json:"content"`
+	} `json:"messages"`
+}
+
+func TestGetPromptEmbedsFiles(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := os.WriteFile(filepath.Join(dir, "review-file.yaml"), []byte(embedPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	root := t.TempDir()
+	writeEmbedFile(t, root, "src/main.go", []byte("package main\n"))
+	writeEmbedFile(t, root, "logo.png", []byte("\x89PNG\r\n\x1a\n\x00\x00"))
+	writeEmbedFile(t, root, "big.txt", []byte(strings.Repeat("x", 64)))
+	outside := writeEmbedFile(t, t.TempDir(), "secret.txt", []byte("secret"))
+	srv.config.EmbedRoot = root
+	srv.config.EmbedMaxBytes = 32
+
+	get := func(path string) (embedResult, string) {
+		var result embedResult
+		params := map[string]interface{}{"name": "review-file", "arguments": map[string]string{"path": path}}
+		msg := call(t, srv, "prompts/get", params, &result)
+		return result, msg
+	}
+
+	result, msg := get("src/main.go")
+	if msg != "" {
+		t.Fatalf("prompts/get failed: %s", msg)
+	}
+	if len(result.Messages) != 2 || result.Messages[0].Content.Text != "Please review this file:" {
+		t.Fatalf("Unexpected messages: %+v", result.Messages)
+	}
+	embedded := result.Messages[1].Content
+	if embedded.Type != "resource" || embedded.Resource.URI != "file://src/main.go" || embedded.Resource.Text != "package main\n" {
+		t.Errorf("Expected embedded text resource, got %+v", embedded)
+	}
+	if !strings.HasPrefix(embedded.Resource.MIMEType, "text/") {
+		t.Errorf("Expected text MIME type, got %q", embedded.Resource.MIMEType)
+	}
+
+	result, msg = get("logo.png")
+	if msg != "" {
+		t.Fatalf("prompts/get failed: %s", msg)
+	}
+	if resource := result.Messages[1].Content.Resource; resource.MIMEType != "image/png" || resource.Blob == "" || resource.Text != "" {
+		t.Errorf("Expected base64 blob for binary file, got %+v", resource)
+	}
+
+	failures := []struct {
+		path    string
+		message string
+	}{
+		{"big.txt", "file is 64 bytes, limit is 32"},
+		{"../" + filepath.Base(filepath.Dir(outside)) + "/secret.txt", "outside the embed root"},
+		{outside, "outside the embed root"},
+		{"missing.txt", "no such file"},
+	}
+	for _, tt := range failures {
+		if _, msg := get(tt.path); !strings.Contains(msg, tt.message) {
+			t.Errorf("Embedding %s: expected error containing %q, got %q", tt.path, tt.message, msg)
+		}
+	}
+
+	// render_prompt embeds the file like prompts/get and records no usage
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"id": "review-file", "arguments": map[string]interface{}{"path": "src/main.go"}}
+	rendered, err := srv.handleRenderPrompt(context.Background(), request)
+	if err != nil || rendered.IsError {
+		t.Fatalf("render_prompt failed: %v %+v", err, rendered)
+	}
+	if text := rendered.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "<resource file://src/main.go (") || !strings.HasSuffix(text, "package main\n") {
+		t.Errorf("Expected the file contents in the rendered prompt, got %q", text)
+	}
+	if stats, _ := srv.GetUsageStats("review-file"); stats.UsageCount != 2 {
+		t.Errorf("Expected only prompts/get to record usage, got %d uses", stats.UsageCount)
+	}
+
+	srv.config.EmbedRoot = ""
+	if _, msg := get("src/main.go"); !strings.Contains(msg, "file embedding is disabled") {
+		t.Errorf("Expected embedding to be disabled without a root, got %q", msg)
+	}
+}
+
+// writeEmbedFile writes data to root/name and returns the path
+func writeEmbedFile(t *testing.T, root, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
@@ -1,19 +1,168 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
-	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"github.com/markopolo123/prompt-mcp/internal/usage"
 )
 
-// resolvePromptContent resolves arguments in prompt content
+// resolvePromptMessages resolves arguments in every message of a prompt
+func (s *Server) resolvePromptMessages(promptObj *prompt.Prompt, args map[string]interface{}) ([]prompt.Message, error) {
+	argValues, err := s.resolveArgumentValues(promptObj, args)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Validate required arguments
+	for _, arg := range promptObj.Arguments {
+		if arg.Required {
+			if _, exists := argValues[arg.Name]; !exists {
+				return nil, fmt.Errorf("required argument '%s' not provided", arg.Name)
+			}
+		}
+	}
+	
+	return renderMessages(promptObj, argValues)
+}
+
+// resolvePromptContent resolves arguments in prompt content. Multi-message
+// prompts are rendered as text with a heading per message.
 func (s *Server) resolvePromptContent(promptObj *prompt.Prompt, args map[string]interface{}) (string, error) {
-	content := promptObj.Prompt
+	messages, err := s.resolvePromptMessages(promptObj, args)
+	if err != nil {
+		return "", err
+	}
+	
+	return formatMessages(messages), nil
+}
+
+// resolveDefaultContent renders prompt content using only argument defaults.
+// Placeholders for arguments without a default are left in place.
+func (s *Server) resolveDefaultContent(promptObj *prompt.Prompt) (string, error) {
+	argValues, err := s.resolveArgumentValues(promptObj, nil)
+	if err != nil {
+		return "", err
+	}
+	
+	messages, err := renderMessages(promptObj, argValues)
+	if err != nil {
+		return "", err
+	}
+	
+	return formatMessages(messages), nil
+}
+
+// renderMessages renders the content of each message of a prompt, keeping
+// the message order
+func renderMessages(promptObj *prompt.Prompt, argValues map[string]interface{}) ([]prompt.Message, error) {
+	conversation := promptObj.Conversation()
 	
+	messages := make([]prompt.Message, 0, len(conversation))
+	for i, message := range conversation {
+		// Resource messages are templated in their URI
+		template := message.Content
+		if message.IsResource() {
+			template = message.URI
+		}
+		
+		rendered, err := renderTemplate(template, argValues)
+		if err != nil {
+			if len(conversation) > 1 {
+				return nil, fmt.Errorf("message %d (%s): %w", i, message.Role, err)
+			}
+			return nil, err
+		}
+		
+		if message.IsResource() {
+			message.URI = rendered
+		} else {
+			message.Content = rendered
+		}
+		messages = append(messages, message)
+	}
+	
+	return messages, nil
+}
+
+// formatMessages formats rendered messages as a single text. A lone user
+// message is returned as is. Embedded resources are shown by URI and not read.
+func formatMessages(messages []prompt.Message) string {
+	if len(messages) == 1 && messages[0].Role == prompt.MessageRoleUser && !messages[0].IsResource() {
+		return messages[0].Content
+	}
+	
+	sections := make([]string, len(messages))
+	for i, message := range messages {
+		if message.IsResource() {
+			sections[i] = fmt.Sprintf("[%s]\n<resource %s>", message.Role, message.URI)
+			continue
+		}
+		sections[i] = fmt.Sprintf("[%s]\n%s", message.Role, strings.TrimRight(message.Content, "\n"))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// FormatPromptResult formats the messages of a prompts/get result as text. A
+// lone user text message is printed as is; otherwise each message is headed
+// by its role and embedded resources are shown with their URI.
+func FormatPromptResult(result *mcp.GetPromptResult) string {
+	if len(result.Messages) == 1 && result.Messages[0].Role == mcp.RoleUser {
+		if text, ok := result.Messages[0].Content.(mcp.TextContent); ok {
+			return ensureNewline(text.Text)
+		}
+	}
+
+	sections := make([]string, len(result.Messages))
+	for i, message := range result.Messages {
+		sections[i] = fmt.Sprintf("[%s]\n%s", message.Role, strings.TrimRight(formatContent(message.Content), "\n"))
+	}
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+// formatContent formats the content of a prompt message
+func formatContent(content mcp.Content) string {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case mcp.EmbeddedResource:
+		switch r := c.Resource.(type) {
+		case mcp.TextResourceContents:
+			return fmt.Sprintf("<resource %s (%s)>\n%s", r.URI, r.MIMEType, r.Text)
+		case mcp.BlobResourceContents:
+			return fmt.Sprintf("<resource %s (%s), %d bytes base64 encoded>", r.URI, r.MIMEType, len(r.Blob))
+		}
+	}
+	return fmt.Sprintf("<%T content>", content)
+}
+
+// ensureNewline terminates text with a newline
+func ensureNewline(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
+
+// mcpRole maps a message role to an MCP role. MCP prompts have no system
+// role, so system messages are sent as user messages.
+func mcpRole(role prompt.MessageRole) mcp.Role {
+	if role == prompt.MessageRoleAssistant {
+		return mcp.RoleAssistant
+	}
+	return mcp.RoleUser
+}
+
+// resolveArgumentValues merges defaults with provided arguments and converts
+// each value to its declared type
+func (s *Server) resolveArgumentValues(promptObj *prompt.Prompt, args map[string]interface{}) (map[string]interface{}, error) {
 	// Create a map of argument values, including defaults
 	argValues := make(map[string]interface{})
 	
@@ -31,24 +180,15 @@ func (s *Server) resolvePromptContent(promptObj *prompt.Prompt, args map[string]
 		}
 	}
 	
-	// Validate required arguments
-	for _, arg := range promptObj.Arguments {
-		if arg.Required {
-			if _, exists := argValues[arg.Name]; !exists {
-				return "", fmt.Errorf("required argument '%s' not provided", arg.Name)
-			}
-		}
-	}
-	
 	// Validate argument types and convert values
 	for _, arg := range promptObj.Arguments {
 		if value, exists := argValues[arg.Name]; exists {
 			log.Printf("Debug: Converting argument '%s' (type: %s) with value '%v' (%T)", 
 				arg.Name, arg.Type, value, value)
-			convertedValue, err := s.convertArgumentValue(value, arg.Type)
+			convertedValue, err := s.convertArgumentValue(value, arg)
 			if err != nil {
 				log.Printf("Debug: Failed to convert argument '%s': %v", arg.Name, err)
-				return "", fmt.Errorf("argument '%s': %w", arg.Name, err)
+				return nil, fmt.Errorf("argument '%s': %w", arg.Name, err)
 			}
 			argValues[arg.Name] = convertedValue
 			log.Printf("Debug: Converted argument '%s' to '%v' (%T)", 
@@ -56,25 +196,70 @@ func (s *Server) resolvePromptContent(promptObj *prompt.Prompt, args map[string]
 		}
 	}
 	
-	// Replace placeholders in content
-	variablePattern := regexp.MustCompile(`\{\{(\w+)\}\}`)
-	result := variablePattern.ReplaceAllStringFunc(content, func(match string) string {
-		// Extract variable name
-		varName := strings.Trim(match, "{}")
-		
-		if value, exists := argValues[varName]; exists {
-			return fmt.Sprintf("%v", value)
-		}
-		
-		// Return original if not found (shouldn't happen due to validation)
-		return match
-	})
+	return argValues, nil
+}
+
+// renderTemplate renders prompt content with argument values
+func renderTemplate(content string, argValues map[string]interface{}) (string, error) {
+	template, err := prompt.ParseTemplate(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid template: %w", err)
+	}
 	
-	return result, nil
+	return template.Render(argValues)
 }
 
 // convertArgumentValue converts and validates argument values based on their type
-func (s *Server) convertArgumentValue(value interface{}, argType prompt.ArgumentType) (interface{}, error) {
+func (s *Server) convertArgumentValue(value interface{}, arg prompt.Argument) (interface{}, error) {
+	if arg.Type == prompt.ArgumentTypeArray {
+		return s.convertArrayValue(value, arg)
+	}
+	return s.convertScalarValue(value, arg.Type, arg.Values)
+}
+
+// convertArrayValue converts an array argument. Lists are accepted as they
+// are; strings may hold a JSON array or comma-separated items.
+func (s *Server) convertArrayValue(value interface{}, arg prompt.Argument) (interface{}, error) {
+	var items []interface{}
+	
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case string:
+		trimmed := strings.TrimSpace(v)
+		switch {
+		case trimmed == "":
+		case strings.HasPrefix(trimmed, "["):
+			if err := json.Unmarshal([]byte(trimmed), &items); err != nil {
+				return nil, fmt.Errorf("cannot parse '%s' as a JSON array: %w", v, err)
+			}
+		default:
+			for _, item := range strings.Split(trimmed, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid array value: %v", value)
+	}
+	
+	converted := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		convertedItem, err := s.convertScalarValue(item, arg.Items, arg.Values)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		converted = append(converted, convertedItem)
+	}
+	
+	return converted, nil
+}
+
+// convertScalarValue converts a single value to a non-array argument type
+func (s *Server) convertScalarValue(value interface{}, argType prompt.ArgumentType, allowed []string) (interface{}, error) {
 	switch argType {
 	case prompt.ArgumentTypeString:
 		return fmt.Sprintf("%v", value), nil
@@ -115,15 +300,33 @@ func (s *Server) convertArgumentValue(value interface{}, argType prompt.Argument
 			return nil, fmt.Errorf("invalid boolean value: %v", value)
 		}
 		
+	case prompt.ArgumentTypeEnum:
+		str := strings.TrimSpace(fmt.Sprintf("%v", value))
+		for _, candidate := range allowed {
+			if strings.EqualFold(candidate, str) {
+				return candidate, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid value '%s', must be one of: %s", str, strings.Join(allowed, ", "))
+		
 	default:
 		return nil, fmt.Errorf("unsupported argument type: %s", argType)
 	}
 }
 
-// updateUsageStats updates the usage statistics for a prompt
-func (s *Server) updateUsageStats(promptObj *prompt.Prompt) {
-	// Note: In a production system, you might want to persist these stats
-	// For now, we just update the in-memory stats
-	promptObj.UsageStats.UsageCount++
-	// promptObj.UsageStats.LastUsed = time.Now() // Uncomment when needed
-}
\ No newline at end of file
+// recordUsage records a use of a prompt in the usage store
+func (s *Server) recordUsage(ctx context.Context, promptObj *prompt.Prompt, args map[string]string) {
+	event := usage.NewEvent(promptObj.Metadata.ID, args, callerFromContext(ctx))
+	if err := s.usage.Record(event); err != nil {
+		log.Printf("Warning: failed to record usage of '%s': %v", promptObj.Metadata.ID, err)
+	}
+}
+
+// callerFromContext returns the name of the MCP client making the request
+func callerFromContext(ctx context.Context) string {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return ""
+	}
+	return session.GetClientInfo().Name
+}
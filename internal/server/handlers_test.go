@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+func TestConvertArgumentValue(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	language := prompt.Argument{Name: "language", Type: prompt.ArgumentTypeEnum, Values: []string{"go", "python", "ts"}}
+	files := prompt.Argument{Name: "files", Type: prompt.ArgumentTypeArray, Items: prompt.ArgumentTypeString}
+	counts := prompt.Argument{Name: "counts", Type: prompt.ArgumentTypeArray, Items: prompt.ArgumentTypeNumber}
+	langs := prompt.Argument{Name: "langs", Type: prompt.ArgumentTypeArray, Items: prompt.ArgumentTypeEnum, Values: []string{"go", "ts"}}
+
+	tests := []struct {
+		name     string
+		arg      prompt.Argument
+		value    interface{}
+		expected interface{}
+		message  string
+	}{
+		{"enum exact", language, "python", "python", ""},
+		{"enum case-insensitive", language, " Go ", "go", ""},
+		{"enum invalid", language, "rust", nil, "must be one of: go, python, ts"},
+		{"array comma-separated", files, "a.go, b.go", []interface{}{"a.go", "b.go"}, ""},
+		{"array JSON", files, `["a.go", "b c.go"]`, []interface{}{"a.go", "b c.go"}, ""},
+		{"array empty", files, "", []interface{}{}, ""},
+		{"array from YAML default", files, []interface{}{"x.go"}, []interface{}{"x.go"}, ""},
+		{"array of numbers", counts, "1, 2.5", []interface{}{1.0, 2.5}, ""},
+		{"array bad item", counts, "1, two", nil, "item 1"},
+		{"array bad JSON", files, "[oops", nil, "JSON array"},
+		{"array of enum", langs, "GO,ts", []interface{}{"go", "ts"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := srv.convertArgumentValue(tt.value, tt.arg)
+			if tt.message != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.message) {
+					t.Errorf("Expected error containing %q, got %v", tt.message, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
+
+const enumPrompt = `metadata:
+  id: "review"
+  name: "Review"
+  description: "Reviews code"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "language"
+    description: "Language of the code"
+    type: "enum"
+    values: ["go", "python", "typescript"]
+    required: true
+  - name: "files"
+    description: "Files to focus on"
+    type: "array"
+    items: "string"
+
+prompt: |
+  Review this {{language}} code. Focus on {{files}}.
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+func TestEnumArgumentsAdvertised(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := os.WriteFile(filepath.Join(dir, "review.yaml"), []byte(enumPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	var list struct {
+		Prompts []struct {
+			Arguments []struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			} `json:"arguments"`
+		} `json:"prompts"`
+	}
+	if msg := call(t, srv, "prompts/list", map[string]interface{}{}, &list); msg != "" {
+		t.Fatalf("prompts/list failed: %s", msg)
+	}
+	args := list.Prompts[0].Arguments
+	if args[0].Description != "Language of the code (one of: go, python, typescript)" {
+		t.Errorf("Unexpected enum description: %q", args[0].Description)
+	}
+	if !strings.Contains(args[1].Description, "list of string") {
+		t.Errorf("Unexpected array description: %q", args[1].Description)
+	}
+
+	var completion struct {
+		Completion struct {
+			Values []string `json:"values"`
+		} `json:"completion"`
+	}
+	params := map[string]interface{}{
+		"ref":      map[string]string{"type": "ref/prompt", "name": "review"},
+		"argument": map[string]string{"name": "language", "value": "py"},
+	}
+	if msg := call(t, srv, "completion/complete", params, &completion); msg != "" {
+		t.Fatalf("completion/complete failed: %s", msg)
+	}
+	if !reflect.DeepEqual(completion.Completion.Values, []string{"python"}) {
+		t.Errorf("Expected [python], got %v", completion.Completion.Values)
+	}
+
+	if msg := call(t, srv, "prompts/get", map[string]interface{}{
+		"name":      "review",
+		"arguments": map[string]string{"language": "rust"},
+	}, nil); !strings.Contains(msg, "must be one of") {
+		t.Errorf("Expected invalid enum value to be rejected, got %q", msg)
+	}
+}
+
+func TestResolvePromptContentLoop(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	p := &prompt.Prompt{
+		Arguments: []prompt.Argument{
+			{Name: "files", Type: prompt.ArgumentTypeArray, Items: prompt.ArgumentTypeString, Required: true},
+		},
+		Prompt: "Review:\n{{#each files}}\n{{@number}}. {{this}}\n{{/each}}",
+	}
+
+	result, err := srv.resolvePromptContent(p, map[string]interface{}{"files": "a.go, b.go"})
+	if err != nil {
+		t.Fatalf("Failed to resolve prompt: %v", err)
+	}
+	if expected := "Review:\n1. a.go\n2. b.go\n"; result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+const fewShotPrompt = `metadata:
+  id: "few-shot"
+  name: "Few Shot"
+  description: "Answers with examples"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "question"
+    description: "Question to answer"
+    type: "string"
+    required: true
+
+messages:
+  - role: "system"
+    content: "Answer briefly."
+  - role: "user"
+    content: "What is 2+2?"
+  - role: "assistant"
+    content: "4"
+  - role: "user"
+    content: "{{question}}"
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+func TestGetPromptMessages(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := os.WriteFile(filepath.Join(dir, "few-shot.yaml"), []byte(fewShotPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	var result struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	params := map[string]interface{}{"name": "few-shot", "arguments": map[string]string{"question": "What is 3+3?"}}
+	if msg := call(t, srv, "prompts/get", params, &result); msg != "" {
+		t.Fatalf("prompts/get failed: %s", msg)
+	}
+
+	expected := []struct{ role, text string }{
+		{"user", "Answer briefly."},
+		{"user", "What is 2+2?"},
+		{"assistant", "4"},
+		{"user", "What is 3+3?"},
+	}
+	if len(result.Messages) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d", len(expected), len(result.Messages))
+	}
+	for i, want := range expected {
+		got := result.Messages[i]
+		if got.Role != want.role || got.Content.Text != want.text {
+			t.Errorf("Message %d: expected %s %q, got %s %q", i, want.role, want.text, got.Role, got.Content.Text)
+		}
+	}
+
+	p, _ := srv.GetLibrary().GetPrompt("few-shot")
+	content, err := srv.resolveDefaultContent(p)
+	if err != nil {
+		t.Fatalf("Failed to render default content: %v", err)
+	}
+	if !strings.HasPrefix(content, "[system]\nAnswer briefly.\n\n[user]\nWhat is 2+2?") {
+		t.Errorf("Unexpected text rendering: %q", content)
+	}
+}
+
+func TestGetPromptDirect(t *testing.T) {
+	srv, dir := newTestServer(t)
+	if err := os.WriteFile(filepath.Join(dir, "few-shot.yaml"), []byte(fewShotPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	result, err := srv.GetPrompt(context.Background(), "few-shot", map[string]string{"question": "What is 3+3?"})
+	if err != nil {
+		t.Fatalf("Failed to render prompt: %v", err)
+	}
+	if len(result.Messages) != 4 {
+		t.Errorf("Expected 4 messages, got %d", len(result.Messages))
+	}
+
+	// Errors match those of a prompts/get request
+	_, err = srv.GetPrompt(context.Background(), "few-shot", nil)
+	msg := call(t, srv, "prompts/get", map[string]interface{}{"name": "few-shot"}, nil)
+	if err == nil || msg == "" || !strings.Contains(msg, err.Error()) {
+		t.Errorf("Expected the prompts/get error %q, got %v", msg, err)
+	}
+
+	if _, err := srv.GetPrompt(context.Background(), "missing", nil); err == nil || !strings.Contains(err.Error(), "prompt 'missing' not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestFormatPromptResult(t *testing.T) {
+	single := &mcp.GetPromptResult{Messages: []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("Hello")),
+	}}
+	if got := FormatPromptResult(single); got != "Hello\n" {
+		t.Errorf("Expected plain text, got %q", got)
+	}
+
+	conversation := &mcp.GetPromptResult{Messages: []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent("What is 2+2?")),
+		mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent("4\n")),
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewEmbeddedResource(mcp.TextResourceContents{
+			URI: "file:///notes.txt", MIMEType: "text/plain", Text: "notes",
+		})),
+	}}
+	expected := "[user]\nWhat is 2+2?\n\n[assistant]\n4\n\n[user]\n<resource file:///notes.txt (text/plain)>\nnotes\n"
+	if got := FormatPromptResult(conversation); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeVersionedPrompt writes a prompt whose name and body both carry version
+func writeVersionedPrompt(t *testing.T, dir string, version int) {
+	t.Helper()
+
+	content := fmt.Sprintf(`metadata:
+  id: "versioned"
+  name: "Version %d"
+  description: "A prompt rewritten during the test"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+prompt: |
+  Body %d
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`, version, version)
+
+	if err := os.WriteFile(filepath.Join(dir, "versioned.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+}
+
+// Run with -race: concurrent GetPrompt calls must each see one consistent
+// snapshot while reloads swap the library underneath them.
+func TestConcurrentGetPromptDuringReload(t *testing.T) {
+	srv, dir := newTestServer(t)
+	writeVersionedPrompt(t, dir, 0)
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+	startGeneration := srv.GetLibrary().Generation
+
+	const reloads = 20
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				var result struct {
+					Description string `json:"description"`
+					Messages    []struct {
+						Content struct {
+							Text string `json:"text"`
+						} `json:"content"`
+					} `json:"messages"`
+				}
+				if msg := call(t, srv, "prompts/get", map[string]interface{}{"name": "versioned"}, &result); msg != "" {
+					t.Errorf("prompts/get failed: %s", msg)
+					return
+				}
+
+				// Name and body come from the same snapshot
+				version := strings.TrimPrefix(result.Description, "Version ")
+				if want := "Body " + version + "\n"; result.Messages[0].Content.Text != want {
+					t.Errorf("Mixed snapshot: %q with body %q", result.Description, result.Messages[0].Content.Text)
+					return
+				}
+			}
+		}()
+	}
+
+	for version := 1; version <= reloads; version++ {
+		writeVersionedPrompt(t, dir, version)
+		if err := srv.Reload(); err != nil {
+			t.Fatalf("Failed to reload: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if got := srv.GetLibrary().Generation; got != startGeneration+reloads {
+		t.Errorf("Expected generation %d, got %d", startGeneration+reloads, got)
+	}
+}
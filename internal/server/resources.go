@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+const (
+	// renderedSuffix is appended to a prompt URI to address its rendered content
+	renderedSuffix = "/rendered"
+
+	// promptURITemplate addresses a single prompt by category and file name
+	promptURITemplate = "prompt://{category}/{name}"
+
+	// categoryURITemplate addresses the index of prompts in a category
+	categoryURITemplate = "prompt://{category}"
+
+	// diagnosticsURI addresses the report of prompt files that failed to load
+	diagnosticsURI = "prompt-mcp://diagnostics"
+
+	mimeTypeYAML = "application/yaml"
+	mimeTypeText = "text/plain"
+	mimeTypeJSON = "application/json"
+)
+
+// categoryEntry describes a prompt in a category index
+type categoryEntry struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URI         string   `json:"uri"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// registerResourceTemplates registers the prompt:// resource templates
+func (s *Server) registerResourceTemplates() {
+	s.mcpServer.AddResourceTemplates(
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(promptURITemplate, "Prompt definition",
+				mcp.WithTemplateDescription("Raw YAML definition of a prompt, addressed by category and file name"),
+				mcp.WithTemplateMIMEType(mimeTypeYAML),
+			),
+			Handler: s.handlePromptTemplate,
+		},
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(categoryURITemplate, "Prompt category",
+				mcp.WithTemplateDescription("Index of the prompts in a category"),
+				mcp.WithTemplateMIMEType(mimeTypeJSON),
+			),
+			Handler: s.handleCategoryTemplate,
+		},
+	)
+}
+
+// registerDiagnosticsResource registers the load diagnostics resource
+func (s *Server) registerDiagnosticsResource() {
+	s.mcpServer.AddResource(
+		mcp.NewResource(diagnosticsURI, "Prompt load diagnostics",
+			mcp.WithResourceDescription("Prompt files that were skipped because they failed to load, with the reason"),
+			mcp.WithMIMEType(mimeTypeJSON),
+		),
+		s.handleDiagnosticsResource,
+	)
+}
+
+// registerResources applies a library diff to the registered resources. Each
+// prompt is exposed as its raw YAML and as its content rendered with defaults.
+func (s *Server) registerResources(previous, library *prompt.PromptLibrary, diff prompt.LibraryDiff) {
+	var stale []string
+	for _, id := range append(append([]string{}, diff.Removed...), diff.Modified...) {
+		if p, exists := previous.GetPrompt(id); exists {
+			uri := s.storage.GetPromptURI(p.FilePath)
+			stale = append(stale, uri, uri+renderedSuffix)
+		}
+	}
+	if len(stale) > 0 {
+		s.mcpServer.DeleteResources(stale...)
+	}
+
+	var changed []server.ServerResource
+	for _, id := range append(append([]string{}, diff.Added...), diff.Modified...) {
+		p, _ := library.GetPrompt(id)
+		uri := s.storage.GetPromptURI(p.FilePath)
+
+		changed = append(changed,
+			server.ServerResource{
+				Resource: mcp.NewResource(uri, p.Metadata.Name,
+					mcp.WithResourceDescription(p.Metadata.Description),
+					mcp.WithMIMEType(mimeTypeYAML),
+				),
+				Handler: s.handlePromptResource,
+			},
+			server.ServerResource{
+				Resource: mcp.NewResource(uri+renderedSuffix, p.Metadata.Name+" (rendered)",
+					mcp.WithResourceDescription("Prompt content rendered with default argument values"),
+					mcp.WithMIMEType(mimeTypeText),
+				),
+				Handler: s.handleRenderedResource,
+			},
+		)
+	}
+	if len(changed) > 0 {
+		s.mcpServer.AddResources(changed...)
+	}
+}
+
+// handlePromptResource serves the raw YAML of a prompt
+func (s *Server) handlePromptResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+
+	p, exists := s.findPromptByURI(uri)
+	if !exists {
+		return nil, fmt.Errorf("prompt not found for URI '%s'", uri)
+	}
+
+	data, err := os.ReadFile(p.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt file: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: mimeTypeYAML, Text: string(data)},
+	}, nil
+}
+
+// handleRenderedResource serves a prompt rendered with its default arguments
+func (s *Server) handleRenderedResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+
+	p, exists := s.findPromptByURI(strings.TrimSuffix(uri, renderedSuffix))
+	if !exists {
+		return nil, fmt.Errorf("prompt not found for URI '%s'", uri)
+	}
+
+	content, err := s.resolveDefaultContent(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: mimeTypeText, Text: content},
+	}, nil
+}
+
+// handleDiagnosticsResource serves the load report of the current library
+func (s *Server) handleDiagnosticsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(s.loadReport(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode diagnostics: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: request.Params.URI, MIMEType: mimeTypeJSON, Text: string(data)},
+	}, nil
+}
+
+// handlePromptTemplate serves prompt://{category}/{name} lookups
+func (s *Server) handlePromptTemplate(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return s.handlePromptResource(ctx, request)
+}
+
+// handleCategoryTemplate serves the index of prompts in a category
+func (s *Server) handleCategoryTemplate(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	category := strings.TrimPrefix(uri, "prompt://")
+
+	entries := []categoryEntry{}
+	for _, p := range s.GetLibrary().ListPrompts() {
+		if s.storage.GetCategoryFromPath(p.FilePath) != category {
+			continue
+		}
+		entries = append(entries, categoryEntry{
+			ID:          p.Metadata.ID,
+			Name:        p.Metadata.Name,
+			Description: p.Metadata.Description,
+			URI:         s.storage.GetPromptURI(p.FilePath),
+			Tags:        p.Metadata.Tags,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("category '%s' not found", category)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode category index: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: mimeTypeJSON, Text: string(data)},
+	}, nil
+}
+
+// findPromptByURI looks up a prompt in the current library by its prompt:// URI
+func (s *Server) findPromptByURI(uri string) (*prompt.Prompt, bool) {
+	library := s.GetLibrary()
+	if library == nil {
+		return nil, false
+	}
+
+	for _, p := range library.ListPrompts() {
+		if s.storage.GetPromptURI(p.FilePath) == uri {
+			return p, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+const greetingPrompt = `metadata:
+  id: "greeting"
+  name: "Greeting"
+  description: "Greets someone"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+arguments:
+  - name: "name"
+    description: "Who to greet"
+    type: "string"
+    required: true
+  - name: "greeting"
+    description: "Greeting to use"
+    type: "string"
+    required: false
+    default: "Hello"
+
+prompt: |
+  {{greeting}}, {{name}}!
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`
+
+type readResult struct {
+	Contents []struct {
+		URI      string `json:"uri"`
+		MIMEType string `json:"mimeType"`
+		Text     string `json:"text"`
+	} `json:"contents"`
+}
+
+func TestPromptResources(t *testing.T) {
+	srv, dir := newTestServer(t)
+
+	categoryDir := filepath.Join(dir, "social")
+	if err := os.MkdirAll(categoryDir, 0755); err != nil {
+		t.Fatalf("Failed to create category dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(categoryDir, "greeting.yaml"), []byte(greetingPrompt), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	var list struct {
+		Resources []struct {
+			URI string `json:"uri"`
+		} `json:"resources"`
+	}
+	if msg := call(t, srv, "resources/list", map[string]interface{}{}, &list); msg != "" {
+		t.Fatalf("resources/list failed: %s", msg)
+	}
+	// Two resources per prompt, plus the load diagnostics
+	if len(list.Resources) != 3 {
+		t.Errorf("Expected 3 resources, got %+v", list.Resources)
+	}
+
+	var raw readResult
+	if msg := call(t, srv, "resources/read", map[string]string{"uri": "prompt://social/greeting"}, &raw); msg != "" {
+		t.Fatalf("Reading raw prompt failed: %s", msg)
+	}
+	if len(raw.Contents) != 1 || raw.Contents[0].Text != greetingPrompt {
+		t.Errorf("Expected raw YAML content, got %+v", raw.Contents)
+	}
+
+	var rendered readResult
+	if msg := call(t, srv, "resources/read", map[string]string{"uri": "prompt://social/greeting/rendered"}, &rendered); msg != "" {
+		t.Fatalf("Reading rendered prompt failed: %s", msg)
+	}
+	if len(rendered.Contents) != 1 || rendered.Contents[0].Text != "Hello, {{name}}!\n" {
+		t.Errorf("Expected defaults rendered and required placeholder kept, got %+v", rendered.Contents)
+	}
+
+	var category readResult
+	if msg := call(t, srv, "resources/read", map[string]string{"uri": "prompt://social"}, &category); msg != "" {
+		t.Fatalf("Reading category failed: %s", msg)
+	}
+	var entries []categoryEntry
+	if err := json.Unmarshal([]byte(category.Contents[0].Text), &entries); err != nil {
+		t.Fatalf("Failed to decode category index: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != "greeting" || entries[0].URI != "prompt://social/greeting" {
+		t.Errorf("Unexpected category index: %+v", entries)
+	}
+
+	if msg := call(t, srv, "resources/read", map[string]string{"uri": "prompt://missing"}, nil); !strings.Contains(msg, "not found") {
+		t.Errorf("Expected missing category error, got %q", msg)
+	}
+}
+
+func TestLoadDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := NewServer(Config{
+		Name:            "test",
+		Version:         "0.0.0",
+		PromptsDir:      dir,
+		LoadMode:        prompt.LoadLenient,
+		EnablePrompts:   true,
+		EnableResources: true,
+		EnableTools:     true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	writeTestPrompt(t, dir, "hello", "Hello")
+	broken := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(broken, []byte("metadata: [unclosed\n"), 0644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Expected lenient load to succeed, got: %v", err)
+	}
+
+	var resource readResult
+	if msg := call(t, srv, "resources/read", map[string]string{"uri": diagnosticsURI}, &resource); msg != "" {
+		t.Fatalf("Reading diagnostics failed: %s", msg)
+	}
+	text, _ := callTool(t, srv, "list_diagnostics", nil)
+
+	for _, data := range []string{resource.Contents[0].Text, text} {
+		var report loadReport
+		if err := json.Unmarshal([]byte(data), &report); err != nil {
+			t.Fatalf("Failed to decode diagnostics: %v", err)
+		}
+		if report.Mode != prompt.LoadLenient || report.Loaded != 1 || report.Skipped != 1 {
+			t.Errorf("Expected 1 loaded and 1 skipped prompt in lenient mode, got %+v", report)
+		}
+		if len(report.Diagnostics) != 1 || report.Diagnostics[0].FilePath != broken || !strings.Contains(report.Diagnostics[0].Message, "failed to parse YAML") {
+			t.Errorf("Expected a diagnostic for the broken file, got %+v", report.Diagnostics)
+		}
+	}
+
+	// Fixing the file clears the diagnostic on reload
+	writeTestPrompt(t, dir, "broken", "Fixed")
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if report := srv.loadReport(); report.Loaded != 2 || len(report.Diagnostics) != 0 {
+		t.Errorf("Expected no diagnostics after fixing the file, got %+v", report)
+	}
+}
@@ -2,29 +2,52 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"github.com/markopolo123/prompt-mcp/internal/search"
 	"github.com/markopolo123/prompt-mcp/internal/storage"
+	"github.com/markopolo123/prompt-mcp/internal/usage"
 )
 
 // Server represents the MCP server
 type Server struct {
 	mcpServer *server.MCPServer
 	storage   *storage.FileSystemStorage
-	library   *prompt.PromptLibrary
+	library   atomic.Pointer[prompt.PromptLibrary]
+	index     atomic.Pointer[search.Index]
+	reloadMu  sync.Mutex // serialises LoadPrompts
+	writeMu   sync.Mutex // serialises prompt writes by the management tools
+	requests  requestTracker
+	usage     usage.Store
 	config    Config
 }
 
 // Config holds server configuration
 type Config struct {
-	Name         string
-	Version      string
-	PromptsDir   string
-	WatchChanges bool
+	Name          string
+	Version       string
+	PromptsDir    string
+	WatchChanges  bool
+	WatchDebounce time.Duration
+	LoadMode      prompt.LoadMode // how invalid prompt files are handled; strict if empty
+	Transport     string          // one of the Transport* constants; stdio if empty
+	ListenAddr    string          // listen address for HTTP transports
+	Usage         usage.Config
+	EmbedRoot     string // directory resource messages may read files from; disabled if empty
+	EmbedMaxBytes int64  // largest embeddable file; DefaultEmbedMaxBytes if zero
+
+	// MCP capabilities offered to clients
+	EnablePrompts   bool
+	EnableResources bool
+	EnableTools     bool
 }
 
 // NewServer creates a new MCP server
@@ -33,6 +56,8 @@ func NewServer(config Config) (*Server, error) {
 	storageConfig := storage.Config{
 		PromptsDir:   config.PromptsDir,
 		WatchChanges: config.WatchChanges,
+		Debounce:     config.WatchDebounce,
+		LoadMode:     config.LoadMode,
 	}
 	
 	storage, err := storage.NewFileSystemStorage(storageConfig)
@@ -40,66 +65,149 @@ func NewServer(config Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	usageStore, err := usage.Open(config.Usage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage stats store: %w", err)
+	}
+
 	// Create server instance
 	srv := &Server{
 		storage: storage,
+		usage:   usageStore,
 		config:  config,
 	}
 
-	// Create MCP server with prompt capabilities
-	mcpServer := server.NewMCPServer(config.Name, config.Version, 
-		server.WithPromptCapabilities(true),
-	)
+	// Create MCP server with the configured capabilities
+	var options []server.ServerOption
+	if config.EnablePrompts {
+		options = append(options,
+			server.WithPromptCapabilities(true),
+			server.WithCompletions(),
+			server.WithPromptCompletionProvider(srv),
+		)
+	}
+	if config.EnableResources {
+		options = append(options, server.WithResourceCapabilities(false, true))
+	}
+	if config.EnableTools {
+		options = append(options, server.WithToolCapabilities(true))
+	}
+
+	mcpServer := server.NewMCPServer(config.Name, config.Version, options...)
 
 	srv.mcpServer = mcpServer
 
+	if config.EnableResources {
+		srv.registerResourceTemplates()
+		srv.registerDiagnosticsResource()
+	}
+	if config.EnableTools {
+		srv.registerTools()
+	}
+
 	return srv, nil
 }
 
-// LoadPrompts loads all prompts from storage
+// LoadPrompts loads all prompts from storage and swaps them in atomically.
+// If loading fails the previously loaded library stays in place.
 func (s *Server) LoadPrompts() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
 	library, err := s.storage.LoadLibrary()
 	if err != nil {
 		return fmt.Errorf("failed to load prompt library: %w", err)
 	}
 
-	s.library = library
-	s.registerPrompts()
-	
-	log.Printf("Loaded %d prompts", len(library.Prompts))
+	previous := s.library.Load()
+	if previous != nil {
+		library.Generation = previous.Generation + 1
+	}
+	s.index.Store(search.NewIndex(library, s.storage.GetCategoryFromPath))
+	s.library.Store(library)
+
+	diff := prompt.DiffLibraries(previous, library)
+	if s.config.EnablePrompts {
+		s.registerPrompts(library, diff)
+	}
+	if s.config.EnableResources {
+		s.registerResources(previous, library, diff)
+	}
+
+	if previous != nil && !diff.Empty() {
+		log.Printf("Prompt changes: %d added, %d removed, %d modified",
+			len(diff.Added), len(diff.Removed), len(diff.Modified))
+	}
+	log.Printf("Loaded %d prompts (generation %d)", library.Len(), library.Generation)
+	if len(library.Diagnostics) > 0 {
+		log.Printf("Skipped %d invalid prompt files:", len(library.Diagnostics))
+		for _, diagnostic := range library.Diagnostics {
+			log.Printf("  %s", diagnostic)
+		}
+	}
 	return nil
 }
 
-// registerPrompts registers all loaded prompts with the MCP server
-func (s *Server) registerPrompts() {
-	for _, p := range s.library.ListPrompts() {
-		// Create prompt options
-		options := []mcp.PromptOption{
-			mcp.WithPromptDescription(p.Metadata.Description),
-		}
-		
-		// Add arguments
-		for _, arg := range p.Arguments {
-			argOptions := []mcp.ArgumentOption{
-				mcp.ArgumentDescription(arg.Description),
-			}
-			if arg.Required {
-				argOptions = append(argOptions, mcp.RequiredArgument())
-			}
-			options = append(options, mcp.WithArgument(arg.Name, argOptions...))
-		}
+// registerPrompts applies a library diff to the MCP server. Removed prompts
+// are deleted and added or modified prompts are (re)registered; the MCP
+// server sends notifications/prompts/list_changed to connected clients for
+// each batch because the listChanged capability is enabled.
+func (s *Server) registerPrompts(library *prompt.PromptLibrary, diff prompt.LibraryDiff) {
+	if len(diff.Removed) > 0 {
+		s.mcpServer.DeletePrompts(diff.Removed...)
+	}
+
+	changed := make([]server.ServerPrompt, 0, len(diff.Added)+len(diff.Modified))
+	for _, id := range append(append([]string{}, diff.Added...), diff.Modified...) {
+		p, _ := library.GetPrompt(id)
+		changed = append(changed, server.ServerPrompt{
+			Prompt:  newMCPPrompt(p),
+			Handler: s.createPromptHandler(p.Metadata.ID),
+		})
+	}
+
+	if len(changed) > 0 {
+		s.mcpServer.AddPrompts(changed...)
+	}
+}
 
-		// Create prompt
-		promptDef := mcp.NewPrompt(p.Metadata.ID, options...)
+// newMCPPrompt builds the MCP prompt definition for a loaded prompt
+func newMCPPrompt(p *prompt.Prompt) mcp.Prompt {
+	// Create prompt options
+	options := []mcp.PromptOption{
+		mcp.WithPromptDescription(p.Metadata.Description),
+	}
 
-		// Register the prompt with its handler
-		s.mcpServer.AddPrompt(promptDef, s.createPromptHandler(p))
+	// Add arguments
+	for _, arg := range p.Arguments {
+		argOptions := []mcp.ArgumentOption{
+			mcp.ArgumentDescription(argumentDescription(arg)),
+		}
+		if arg.Required {
+			argOptions = append(argOptions, mcp.RequiredArgument())
+		}
+		options = append(options, mcp.WithArgument(arg.Name, argOptions...))
 	}
+
+	return mcp.NewPrompt(p.Metadata.ID, options...)
 }
 
-// createPromptHandler creates a handler function for a specific prompt
-func (s *Server) createPromptHandler(p *prompt.Prompt) func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+// createPromptHandler creates a handler function for a specific prompt. The
+// handler resolves the prompt from the library snapshot current when the
+// request starts and uses that snapshot for the whole request, so a reload
+// never changes a prompt underneath an in-flight request.
+func (s *Server) createPromptHandler(id string) func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if !s.requests.begin() {
+			return nil, errShuttingDown
+		}
+		defer s.requests.end()
+
+		p, exists := s.GetLibrary().GetPrompt(id)
+		if !exists {
+			return nil, fmt.Errorf("prompt '%s' is no longer available", id)
+		}
+
 		// Debug: Log incoming arguments
 		log.Printf("Debug: Prompt '%s' received arguments: %+v", p.Metadata.ID, request.Params.Arguments)
 		
@@ -110,29 +218,54 @@ func (s *Server) createPromptHandler(p *prompt.Prompt) func(ctx context.Context,
 			args[key] = value
 		}
 		
-		// Resolve arguments and substitute in prompt content
-		resolvedContent, err := s.resolvePromptContent(p, args)
+		result, err := s.promptResult(ctx, p, args)
 		if err != nil {
-			return nil, fmt.Errorf("failed to resolve prompt content: %w", err)
+			return nil, err
 		}
 
 		// Update usage statistics
-		s.updateUsageStats(p)
-
-		// Return the resolved prompt
-		return mcp.NewGetPromptResult(
-			p.Metadata.Name,
-			[]mcp.PromptMessage{
-				mcp.NewPromptMessage(
-					mcp.RoleUser,
-					mcp.NewTextContent(resolvedContent),
-				),
-			},
-		), nil
+		s.recordUsage(ctx, p, request.Params.Arguments)
+
+		return result, nil
 	}
 }
 
-// Start starts the MCP server
+// promptResult renders a prompt with args into a prompts/get result, keeping
+// the message order and embedding the resources of resource messages
+func (s *Server) promptResult(ctx context.Context, p *prompt.Prompt, args map[string]interface{}) (*mcp.GetPromptResult, error) {
+	resolvedMessages, err := s.resolvePromptMessages(p, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve prompt content: %w", err)
+	}
+
+	messages := make([]mcp.PromptMessage, 0, len(resolvedMessages))
+	for _, message := range resolvedMessages {
+		promptMessage, err := s.newPromptMessage(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, promptMessage)
+	}
+
+	return mcp.NewGetPromptResult(p.Metadata.Name, messages), nil
+}
+
+// GetPrompt renders a prompt exactly as a prompts/get request would, without
+// going through an MCP transport
+func (s *Server) GetPrompt(ctx context.Context, id string, args map[string]string) (*mcp.GetPromptResult, error) {
+	if _, exists := s.GetLibrary().GetPrompt(id); !exists {
+		return nil, fmt.Errorf("prompt '%s' not found", id)
+	}
+
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = id
+	request.Params.Arguments = args
+	return s.createPromptHandler(id)(ctx, request)
+}
+
+// Start starts the MCP server and blocks until ctx is cancelled or the
+// transport stops. Before returning it stops the file watcher and waits for
+// in-flight prompt requests to finish.
 func (s *Server) Start(ctx context.Context) error {
 	// Load prompts before starting
 	if err := s.LoadPrompts(); err != nil {
@@ -141,14 +274,79 @@ func (s *Server) Start(ctx context.Context) error {
 
 	log.Printf("Starting %s v%s", s.config.Name, s.config.Version)
 	log.Printf("Loaded prompts from: %s", s.config.PromptsDir)
-	
-	// Run the MCP server using stdio transport
-	return server.ServeStdio(s.mcpServer)
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	watchDone := make(chan struct{})
+	if s.config.WatchChanges {
+		go func() {
+			defer close(watchDone)
+			s.watchPrompts(watchCtx)
+		}()
+	} else {
+		close(watchDone)
+	}
+
+	serveErr := s.serve(ctx)
+	if errors.Is(serveErr, context.Canceled) {
+		serveErr = nil
+	}
+
+	// Stop watching before draining so no reload races the shutdown
+	stopWatching()
+	<-watchDone
+
+	if err := s.shutdown(); err != nil && serveErr == nil {
+		serveErr = err
+	}
+
+	return serveErr
+}
+
+// shutdown waits for in-flight requests to finish and then flushes usage
+// statistics, so no request can record usage after the store is closed
+func (s *Server) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.requests.drain(ctx); err != nil {
+		return fmt.Errorf("timed out waiting for in-flight requests: %w", err)
+	}
+
+	if err := s.usage.Close(); err != nil {
+		return fmt.Errorf("failed to flush usage stats: %w", err)
+	}
+
+	return nil
+}
+
+// watchPrompts reloads the library whenever the prompts directory changes
+func (s *Server) watchPrompts(ctx context.Context) {
+	log.Printf("Watching %s for changes", s.config.PromptsDir)
+
+	err := s.storage.Watch(ctx, func() {
+		log.Println("Prompt changes detected, reloading")
+		if err := s.Reload(); err != nil {
+			log.Printf("Reload failed, keeping previous prompts: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("File watching stopped: %v", err)
+	}
+}
+
+// GetUsageStats returns the recorded usage statistics for a prompt
+func (s *Server) GetUsageStats(promptID string) (usage.Stats, bool) {
+	return s.usage.Get(promptID)
 }
 
 // GetLibrary returns the current prompt library
 func (s *Server) GetLibrary() *prompt.PromptLibrary {
-	return s.library
+	return s.library.Load()
+}
+
+// GetIndex returns the search index over the current prompt library
+func (s *Server) GetIndex() *search.Index {
+	return s.index.Load()
 }
 
 // Reload reloads prompts from storage
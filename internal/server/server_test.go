@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// testSession is a minimal MCP client session that records notifications
+type testSession struct {
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newTestSession() *testSession {
+	return &testSession{notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (ts *testSession) Initialize()       {}
+func (ts *testSession) Initialized() bool { return true }
+func (ts *testSession) SessionID() string { return "test-session" }
+func (ts *testSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return ts.notifications
+}
+
+// writeTestPrompt writes a minimal valid prompt file into dir
+func writeTestPrompt(t *testing.T, dir, id, body string) string {
+	t.Helper()
+
+	content := fmt.Sprintf(`metadata:
+  id: "%s"
+  name: "Test %s"
+  description: "A test prompt"
+  author: "test"
+  created: "2025-08-27T10:00:00Z"
+  modified: "2025-08-27T10:00:00Z"
+  version: "1.0.0"
+
+prompt: |
+  %s
+
+usage_stats:
+  usage_count: 0
+  last_used: "2025-08-27T10:00:00Z"
+`, id, id, body)
+
+	path := filepath.Join(dir, id+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write prompt file: %v", err)
+	}
+	return path
+}
+
+// newTestServer creates a server over a temporary prompts directory
+func newTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	srv, err := NewServer(Config{
+		Name:            "test",
+		Version:         "0.0.0",
+		PromptsDir:      dir,
+		EnablePrompts:   true,
+		EnableResources: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	return srv, dir
+}
+
+// call sends a JSON-RPC request to the MCP server and decodes its result
+// into result. It returns the JSON-RPC error message, if any.
+func call(t *testing.T, srv *Server, method string, params interface{}, result interface{}) string {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode request: %v", err)
+	}
+
+	response, err := json.Marshal(srv.mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if envelope.Error != nil {
+		return envelope.Error.Message
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(envelope.Result, result); err != nil {
+			t.Fatalf("Failed to decode result: %v", err)
+		}
+	}
+	return ""
+}
+
+// drain returns the methods of all notifications currently queued
+func (ts *testSession) drain() []string {
+	var methods []string
+	for {
+		select {
+		case n := <-ts.notifications:
+			methods = append(methods, n.Method)
+		case <-time.After(50 * time.Millisecond):
+			return methods
+		}
+	}
+}
+
+func TestReloadSendsListChanged(t *testing.T) {
+	srv, dir := newTestServer(t)
+	writeTestPrompt(t, dir, "keep", "Keep me")
+	removed := writeTestPrompt(t, dir, "remove", "Remove me")
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	session := newTestSession()
+	if err := srv.mcpServer.RegisterSession(context.Background(), session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	// Reloading an unchanged library must stay quiet
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+	if got := session.drain(); len(got) != 0 {
+		t.Errorf("Expected no notifications for unchanged library, got %v", got)
+	}
+
+	if err := os.Remove(removed); err != nil {
+		t.Fatalf("Failed to remove prompt: %v", err)
+	}
+	writeTestPrompt(t, dir, "added", "New prompt")
+
+	if err := srv.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+
+	promptsChanged := false
+	for _, method := range session.drain() {
+		switch method {
+		case mcp.MethodNotificationPromptsListChanged:
+			promptsChanged = true
+		case mcp.MethodNotificationResourcesListChanged:
+		default:
+			t.Errorf("Unexpected notification %s", method)
+		}
+	}
+	if !promptsChanged {
+		t.Error("Expected a prompts list_changed notification")
+	}
+
+	if _, exists := srv.GetLibrary().GetPrompt("remove"); exists {
+		t.Error("Expected removed prompt to be gone from the library")
+	}
+	if _, exists := srv.GetLibrary().GetPrompt("added"); !exists {
+		t.Error("Expected added prompt to be in the library")
+	}
+}
+
+func TestGetPromptRecordsUsage(t *testing.T) {
+	srv, dir := newTestServer(t)
+	writeTestPrompt(t, dir, "hello", "Hello")
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if msg := call(t, srv, "prompts/get", map[string]interface{}{"name": "hello"}, nil); msg != "" {
+			t.Fatalf("prompts/get failed: %s", msg)
+		}
+	}
+
+	stats, exists := srv.GetUsageStats("hello")
+	if !exists || stats.UsageCount != 3 {
+		t.Errorf("Expected 3 recorded uses, got %+v", stats)
+	}
+	if stats.LastUsed.IsZero() {
+		t.Error("Expected last used time to be recorded")
+	}
+
+	// Usage must never be written back to the prompt definition
+	p, _ := srv.GetLibrary().GetPrompt("hello")
+	if p.UsageStats.UsageCount != 0 {
+		t.Errorf("Expected prompt definition to be untouched, got %d", p.UsageStats.UsageCount)
+	}
+}
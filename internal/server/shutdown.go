@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errShuttingDown is returned to requests that arrive once shutdown has begun
+var errShuttingDown = errors.New("server is shutting down")
+
+// requestTracker counts in-flight requests so shutdown can wait for them
+type requestTracker struct {
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// begin registers a new request. It returns false once draining has started,
+// in which case the request must be rejected and end must not be called.
+func (t *requestTracker) begin() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return false
+	}
+	t.wg.Add(1)
+	return true
+}
+
+// end marks a request registered with begin as finished
+func (t *requestTracker) end() {
+	t.wg.Done()
+}
+
+// drain stops new requests from starting and waits for in-flight requests to
+// finish or for ctx to be done
+func (t *requestTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
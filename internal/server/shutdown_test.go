@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRequestTrackerDrain(t *testing.T) {
+	var tracker requestTracker
+
+	if !tracker.begin() {
+		t.Fatal("Expected request to start before draining")
+	}
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- tracker.drain(context.Background())
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("Drain returned while a request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if tracker.begin() {
+		t.Error("Expected new requests to be rejected while draining")
+	}
+
+	tracker.end()
+
+	select {
+	case err := <-drained:
+		if err != nil {
+			t.Errorf("Unexpected drain error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Drain did not return after the request finished")
+	}
+}
+
+func TestRequestTrackerDrainTimeout(t *testing.T) {
+	var tracker requestTracker
+	tracker.begin()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := tracker.drain(ctx); err == nil {
+		t.Error("Expected drain to time out with a request still in flight")
+	}
+}
+
+func TestPromptsRejectedAfterShutdown(t *testing.T) {
+	srv, dir := newTestServer(t)
+	writeTestPrompt(t, dir, "hello", "Hello")
+
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+	if err := srv.shutdown(); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	msg := call(t, srv, "prompts/get", map[string]interface{}{"name": "hello"}, nil)
+	if msg != errShuttingDown.Error() {
+		t.Errorf("Expected shutdown error, got %q", msg)
+	}
+}
@@ -0,0 +1,372 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultSearchLimit caps the number of search_prompts results
+const defaultSearchLimit = 20
+
+// promptSummary describes a prompt in tool results
+type promptSummary struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	URI         string   `json:"uri"`
+	Tags        []string `json:"tags,omitempty"`
+	Score       float64  `json:"score,omitempty"` // search rank, higher is better
+}
+
+// tagCount is a tag and the number of prompts that use it
+type tagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// registerTools registers the library management tools
+func (s *Server) registerTools() {
+	s.mcpServer.AddTools(
+		server.ServerTool{
+			Tool: mcp.NewTool("search_prompts",
+				mcp.WithDescription("Search the prompt library by text, tag or category, best match first. All given filters must match."),
+				mcp.WithString("query", mcp.Description("Words to find in the ID, name, description, tags, author or body. Typos are tolerated. Prefix a word with a field to search only that field, as in 'review tag:testing author:team'; fields are id, name, description, tag, author, category and body.")),
+				mcp.WithString("tag", mcp.Description("Only return prompts with exactly this tag")),
+				mcp.WithString("category", mcp.Description("Only return prompts in exactly this category")),
+				mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of results, %d by default; 0 for no limit", defaultSearchLimit))),
+				mcp.WithReadOnlyHintAnnotation(true),
+			),
+			Handler: s.handleSearchPrompts,
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("render_prompt",
+				mcp.WithDescription("Render a prompt with arguments and return the resulting text"),
+				mcp.WithString("id", mcp.Required(), mcp.Description("ID of the prompt to render")),
+				mcp.WithObject("arguments", mcp.Description("Argument values by name; defaults are used for omitted arguments")),
+				mcp.WithReadOnlyHintAnnotation(true),
+			),
+			Handler: s.handleRenderPrompt,
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("create_prompt",
+				mcp.WithDescription("Create a new prompt from a YAML definition. The prompt is validated before it is saved."),
+				mcp.WithString("definition", mcp.Required(), mcp.Description("YAML definition of the prompt, in the prompt file format")),
+				mcp.WithString("category", mcp.Description("Category directory to create the prompt in; the prompts directory itself if empty")),
+				mcp.WithDestructiveHintAnnotation(false),
+			),
+			Handler: s.handleCreatePrompt,
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("update_prompt",
+				mcp.WithDescription("Replace the definition of an existing prompt. The prompt is validated before it is saved."),
+				mcp.WithString("id", mcp.Required(), mcp.Description("ID of the prompt to update")),
+				mcp.WithString("definition", mcp.Required(), mcp.Description("New YAML definition of the prompt, in the prompt file format")),
+				mcp.WithIdempotentHintAnnotation(true),
+			),
+			Handler: s.handleUpdatePrompt,
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("list_tags",
+				mcp.WithDescription("List the tags used in the prompt library with the number of prompts using each"),
+				mcp.WithReadOnlyHintAnnotation(true),
+			),
+			Handler: s.handleListTags,
+		},
+		server.ServerTool{
+			Tool: mcp.NewTool("list_diagnostics",
+				mcp.WithDescription("List the prompt files that were skipped because they failed to load, with the reason. Use this to find out why a prompt is missing."),
+				mcp.WithReadOnlyHintAnnotation(true),
+			),
+			Handler: s.handleListDiagnostics,
+		},
+	)
+}
+
+// handleSearchPrompts searches the library index and applies the exact tag
+// and category filters
+func (s *Server) handleSearchPrompts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	query := request.GetString("query", "")
+	tag := strings.TrimSpace(request.GetString("tag", ""))
+	category := strings.TrimSpace(request.GetString("category", ""))
+	limit := request.GetInt("limit", defaultSearchLimit)
+
+	matches, err := s.GetIndex().Search(query, 0)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := []promptSummary{}
+	for _, match := range matches {
+		p := match.Prompt
+		if tag != "" && !containsTag(p.Metadata.Tags, tag) {
+			continue
+		}
+		if category != "" && s.storage.GetCategoryFromPath(p.FilePath) != category {
+			continue
+		}
+		summary := s.summarizePrompt(p)
+		summary.Score = match.Score
+		results = append(results, summary)
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+
+	return newJSONToolResult(results)
+}
+
+// handleRenderPrompt renders a prompt the way prompts/get would and returns
+// it as text. Like the render command it is a preview, so it is not recorded
+// in usage statistics.
+func (s *Server) handleRenderPrompt(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	id, err := request.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	p, exists := s.GetLibrary().GetPrompt(id)
+	if !exists {
+		return mcp.NewToolResultErrorf("prompt '%s' not found", id), nil
+	}
+
+	args := map[string]interface{}{}
+	if raw, exists := request.GetArguments()["arguments"]; exists && raw != nil {
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return mcp.NewToolResultError("arguments must be an object"), nil
+		}
+		args = values
+	}
+
+	result, err := s.promptResult(ctx, p, args)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(FormatPromptResult(result)), nil
+}
+
+// handleCreatePrompt saves a new prompt and reloads the library
+func (s *Server) handleCreatePrompt(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	definition, err := request.RequireString("definition")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	category := strings.TrimSpace(request.GetString("category", ""))
+	if category != "" && !prompt.IsValidCategory(category) {
+		return mcp.NewToolResultErrorf("invalid category '%s'", category), nil
+	}
+
+	p, err := parseDefinition(definition)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	id := p.Metadata.ID
+	if strings.TrimSpace(id) == "" {
+		return mcp.NewToolResultError("definition must set metadata.id"), nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if _, exists := s.GetLibrary().GetPrompt(id); exists {
+		return mcp.NewToolResultErrorf("prompt '%s' already exists, use update_prompt to change it", id), nil
+	}
+
+	filePath := filepath.Join(s.storage.GetPromptsDir(), category, id+".yaml")
+	if _, err := os.Stat(filePath); err == nil {
+		return mcp.NewToolResultErrorf("file %s already exists", filePath), nil
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if p.Metadata.Created.IsZero() {
+		p.Metadata.Created = now
+	}
+	if p.Metadata.Modified.IsZero() {
+		p.Metadata.Modified = now
+	}
+
+	return s.savePrompt(p, filePath)
+}
+
+// handleUpdatePrompt replaces the definition of an existing prompt and
+// reloads the library
+func (s *Server) handleUpdatePrompt(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	id, err := request.RequireString("id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	definition, err := request.RequireString("definition")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	p, err := parseDefinition(definition)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// The ID may be left out of the definition, but cannot be changed
+	if p.Metadata.ID == "" {
+		p.Metadata.ID = id
+	} else if p.Metadata.ID != id {
+		return mcp.NewToolResultErrorf("definition has ID '%s', expected '%s'; prompts cannot be renamed", p.Metadata.ID, id), nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	existing, exists := s.GetLibrary().GetPrompt(id)
+	if !exists {
+		return mcp.NewToolResultErrorf("prompt '%s' not found", id), nil
+	}
+
+	if p.Metadata.Created.IsZero() {
+		p.Metadata.Created = existing.Metadata.Created
+	}
+	p.Metadata.Modified = time.Now().UTC().Truncate(time.Second)
+
+	return s.savePrompt(p, existing.FilePath)
+}
+
+// handleListTags counts the prompts using each tag
+func (s *Server) handleListTags(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	counts := make(map[string]int)
+	for _, p := range s.GetLibrary().ListPrompts() {
+		for _, tag := range p.Metadata.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]tagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, tagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+
+	return newJSONToolResult(tags)
+}
+
+// handleListDiagnostics reports the prompt files skipped by the last load
+func (s *Server) handleListDiagnostics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !s.requests.begin() {
+		return nil, errShuttingDown
+	}
+	defer s.requests.end()
+
+	return newJSONToolResult(s.loadReport())
+}
+
+// savePrompt validates and writes a prompt, then reloads the library so the
+// change is visible immediately rather than after the file watcher fires.
+// If the library no longer loads, for example because a prompt extending
+// this one breaks, the previous file is restored.
+func (s *Server) savePrompt(p *prompt.Prompt, filePath string) (*mcp.CallToolResult, error) {
+	previous, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return mcp.NewToolResultErrorf("failed to save prompt '%s': %v", p.Metadata.ID, err), nil
+	}
+	existed := err == nil
+
+	if err := s.storage.SavePrompt(p, filePath); err != nil {
+		return mcp.NewToolResultErrorf("failed to save prompt '%s': %v", p.Metadata.ID, err), nil
+	}
+
+	if err := s.Reload(); err != nil {
+		restore := os.Remove
+		if existed {
+			restore = func(name string) error { return os.WriteFile(name, previous, 0644) }
+		}
+		if restoreErr := restore(filePath); restoreErr != nil {
+			return mcp.NewToolResultErrorf("prompt '%s' breaks the library: %v; restoring %s failed: %v", p.Metadata.ID, err, filePath, restoreErr), nil
+		}
+		return mcp.NewToolResultErrorf("prompt '%s' was not saved because the library would fail to load: %v", p.Metadata.ID, err), nil
+	}
+
+	saved, exists := s.GetLibrary().GetPrompt(p.Metadata.ID)
+	if !exists {
+		return mcp.NewToolResultErrorf("prompt '%s' was saved to %s but is not in the reloaded library", p.Metadata.ID, filePath), nil
+	}
+	return newJSONToolResult(s.summarizePrompt(saved))
+}
+
+// parseDefinition parses the YAML definition of a prompt. Validation is left
+// to SavePrompt, which resolves includes and inheritance first.
+func parseDefinition(definition string) (*prompt.Prompt, error) {
+	var p prompt.Prompt
+	if err := yaml.Unmarshal([]byte(definition), &p); err != nil {
+		return nil, fmt.Errorf("failed to parse definition: %w", err)
+	}
+	return &p, nil
+}
+
+// summarizePrompt describes a prompt for tool results
+func (s *Server) summarizePrompt(p *prompt.Prompt) promptSummary {
+	return promptSummary{
+		ID:          p.Metadata.ID,
+		Name:        p.Metadata.Name,
+		Description: p.Metadata.Description,
+		Category:    s.storage.GetCategoryFromPath(p.FilePath),
+		URI:         s.storage.GetPromptURI(p.FilePath),
+		Tags:        p.Metadata.Tags,
+	}
+}
+
+// containsTag reports whether tags contains tag, ignoring case
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// newJSONToolResult returns v as indented JSON text
+func newJSONToolResult(v interface{}) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const greetDefinition = `metadata:
+  id: "greet"
+  name: "Greet"
+  description: "Writes a greeting"
+  author: "test"
+  version: "1.0.0"
+  tags: ["writing", "email"]
+
+arguments:
+  - name: "name"
+    description: "Who to greet"
+    type: "string"
+    required: true
+
+prompt: |
+  Write a greeting for {{name}}.
+`
+
+// toolResult is the shape of a tools/call result
+type toolResult struct {
+	IsError bool `json:"isError"`
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// callTool calls a tool and returns its text and whether it failed
+func callTool(t *testing.T, srv *Server, name string, args map[string]interface{}) (string, bool) {
+	t.Helper()
+
+	var result toolResult
+	if msg := call(t, srv, "tools/call", map[string]interface{}{"name": name, "arguments": args}, &result); msg != "" {
+		t.Fatalf("tools/call %s failed: %s", name, msg)
+	}
+	if len(result.Content) == 0 {
+		t.Fatalf("Expected content from %s", name)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestLibraryTools(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := NewServer(Config{
+		Name:          "test",
+		Version:       "0.0.0",
+		PromptsDir:    dir,
+		EnablePrompts: true,
+		EnableTools:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	writeTestPrompt(t, dir, "hello", "Hello")
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	// Create
+	text, failed := callTool(t, srv, "create_prompt", map[string]interface{}{"definition": greetDefinition, "category": "writing"})
+	if failed {
+		t.Fatalf("create_prompt failed: %s", text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "writing", "greet.yaml")); err != nil {
+		t.Errorf("Expected prompt file in category directory: %v", err)
+	}
+	p, exists := srv.GetLibrary().GetPrompt("greet")
+	if !exists {
+		t.Fatal("Expected created prompt in the library")
+	}
+	if p.Metadata.Created.IsZero() || p.Metadata.Modified.IsZero() {
+		t.Error("Expected created and modified to be filled in")
+	}
+
+	if text, failed := callTool(t, srv, "create_prompt", map[string]interface{}{"definition": greetDefinition}); !failed || !strings.Contains(text, "already exists") {
+		t.Errorf("Expected duplicate create to fail, got %q", text)
+	}
+
+	invalid := strings.Replace(greetDefinition, `"greet"`, `"broken"`, 1)
+	invalid = strings.Replace(invalid, "{{name}}", "{{nme}}", 1)
+	if text, failed := callTool(t, srv, "create_prompt", map[string]interface{}{"definition": invalid}); !failed || !strings.Contains(text, "undefined variable 'nme'") {
+		t.Errorf("Expected invalid prompt to be rejected, got %q", text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.yaml")); !os.IsNotExist(err) {
+		t.Error("Expected invalid prompt not to be written")
+	}
+
+	if text, failed := callTool(t, srv, "create_prompt", map[string]interface{}{"definition": greetDefinition, "category": "../x"}); !failed || !strings.Contains(text, "invalid category") {
+		t.Errorf("Expected invalid category to be rejected, got %q", text)
+	}
+
+	// Search
+	searches := []struct {
+		args     map[string]interface{}
+		expected []string
+	}{
+		{map[string]interface{}{}, []string{"greet", "hello"}},
+		{map[string]interface{}{"query": "GREETING"}, []string{"greet"}},
+		{map[string]interface{}{"query": "greting tag:email"}, []string{"greet"}},
+		{map[string]interface{}{"tag": "email"}, []string{"greet"}},
+		{map[string]interface{}{"limit": 1}, []string{"greet"}},
+		{map[string]interface{}{"category": "uncategorized"}, []string{"hello"}},
+		{map[string]interface{}{"query": "greet", "category": "uncategorized"}, []string{}},
+	}
+	for _, search := range searches {
+		text, failed := callTool(t, srv, "search_prompts", search.args)
+		if failed {
+			t.Fatalf("search_prompts failed: %s", text)
+		}
+		var results []promptSummary
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to decode search results: %v", err)
+		}
+		ids := []string{}
+		for _, result := range results {
+			ids = append(ids, result.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(search.expected, ",") {
+			t.Errorf("Search %v: expected %v, got %v", search.args, search.expected, ids)
+		}
+	}
+
+	// Render
+	text, failed = callTool(t, srv, "render_prompt", map[string]interface{}{"id": "greet", "arguments": map[string]interface{}{"name": "Ada"}})
+	if failed || text != "Write a greeting for Ada.\n" {
+		t.Errorf("Expected rendered prompt, got %q", text)
+	}
+	if text, failed := callTool(t, srv, "render_prompt", map[string]interface{}{"id": "greet"}); !failed || !strings.Contains(text, "required argument 'name' not provided") {
+		t.Errorf("Expected missing argument error, got %q", text)
+	}
+
+	// Update
+	updated := strings.Replace(greetDefinition, "Write a greeting", "Write a short greeting", 1)
+	if text, failed := callTool(t, srv, "update_prompt", map[string]interface{}{"id": "greet", "definition": updated}); failed {
+		t.Fatalf("update_prompt failed: %s", text)
+	}
+	text, _ = callTool(t, srv, "render_prompt", map[string]interface{}{"id": "greet", "arguments": map[string]interface{}{"name": "Ada"}})
+	if text != "Write a short greeting for Ada.\n" {
+		t.Errorf("Expected updated prompt, got %q", text)
+	}
+	updatedPrompt, _ := srv.GetLibrary().GetPrompt("greet")
+	if !updatedPrompt.Metadata.Created.Equal(p.Metadata.Created) {
+		t.Errorf("Expected created to be kept, got %v", updatedPrompt.Metadata.Created)
+	}
+	if text, failed := callTool(t, srv, "update_prompt", map[string]interface{}{"id": "hello", "definition": updated}); !failed || !strings.Contains(text, "cannot be renamed") {
+		t.Errorf("Expected ID mismatch to be rejected, got %q", text)
+	}
+
+	// Tags
+	text, _ = callTool(t, srv, "list_tags", nil)
+	var tags []tagCount
+	if err := json.Unmarshal([]byte(text), &tags); err != nil {
+		t.Fatalf("Failed to decode tags: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != (tagCount{"email", 1}) || tags[1] != (tagCount{"writing", 1}) {
+		t.Errorf("Expected email and writing tags, got %+v", tags)
+	}
+}
+
+func TestUpdatePromptKeepsDescendantsLoading(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := NewServer(Config{
+		Name:          "test",
+		Version:       "0.0.0",
+		PromptsDir:    dir,
+		EnablePrompts: true,
+		EnableTools:   true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := srv.LoadPrompts(); err != nil {
+		t.Fatalf("Failed to load prompts: %v", err)
+	}
+
+	child := `metadata:
+  id: "formal-greet"
+  name: "Formal Greet"
+
+extends: "greet"
+
+prompt: |
+  Write a formal greeting for {{name}}.
+`
+	for _, definition := range []string{greetDefinition, child} {
+		if text, failed := callTool(t, srv, "create_prompt", map[string]interface{}{"definition": definition}); failed {
+			t.Fatalf("create_prompt failed: %s", text)
+		}
+	}
+
+	parentPath := filepath.Join(dir, "greet.yaml")
+	before, err := os.ReadFile(parentPath)
+	if err != nil {
+		t.Fatalf("Failed to read parent: %v", err)
+	}
+
+	// Renaming the argument leaves the child using an undefined variable
+	renamed := strings.NewReplacer(`"name"`, `"person"`, "{{name}}", "{{person}}").Replace(greetDefinition)
+	text, failed := callTool(t, srv, "update_prompt", map[string]interface{}{"id": "greet", "definition": renamed})
+	if !failed || !strings.Contains(text, "was not saved") || !strings.Contains(text, "formal-greet") {
+		t.Errorf("Expected update breaking the child to be rejected, got %q", text)
+	}
+
+	after, err := os.ReadFile(parentPath)
+	if err != nil {
+		t.Fatalf("Failed to read parent: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("Expected parent file to be restored, got:\n%s", after)
+	}
+	if p, _ := srv.GetLibrary().GetPrompt("greet"); p.Arguments[0].Name != "name" {
+		t.Errorf("Expected the library to keep the old parent, got %+v", p.Arguments)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Supported transports
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+const (
+	// DefaultListenAddr is used by the HTTP transports when no address is configured
+	DefaultListenAddr = ":8080"
+
+	// StreamableHTTPPath is the endpoint served by the streamable HTTP transport
+	StreamableHTTPPath = "/mcp"
+
+	// shutdownTimeout bounds how long HTTP transports wait for open requests
+	shutdownTimeout = 10 * time.Second
+)
+
+// IsValidTransport reports whether transport names a supported transport
+func IsValidTransport(transport string) bool {
+	switch transport {
+	case TransportStdio, TransportSSE, TransportStreamableHTTP:
+		return true
+	default:
+		return false
+	}
+}
+
+// serve runs the MCP server on the configured transport until it fails or
+// ctx is cancelled
+func (s *Server) serve(ctx context.Context) error {
+	addr := s.config.ListenAddr
+	if addr == "" {
+		addr = DefaultListenAddr
+	}
+
+	switch s.config.Transport {
+	case "", TransportStdio:
+		// Listen returns once ctx is cancelled or stdin is closed
+		return server.NewStdioServer(s.mcpServer).Listen(ctx, os.Stdin, os.Stdout)
+
+	case TransportSSE:
+		httpServer := &http.Server{Addr: addr}
+		sseServer := server.NewSSEServer(s.mcpServer, server.WithHTTPServer(httpServer))
+		httpServer.Handler = sseServer
+
+		log.Printf("Serving SSE transport on %s", addr)
+		return serveHTTP(ctx, httpServer, sseServer.Shutdown)
+
+	case TransportStreamableHTTP:
+		httpServer := &http.Server{Addr: addr}
+		streamableServer := server.NewStreamableHTTPServer(s.mcpServer,
+			server.WithStreamableHTTPServer(httpServer),
+		)
+
+		mux := http.NewServeMux()
+		mux.Handle(StreamableHTTPPath, streamableServer)
+		httpServer.Handler = mux
+
+		log.Printf("Serving streamable HTTP transport on %s%s", addr, StreamableHTTPPath)
+		return serveHTTP(ctx, httpServer, streamableServer.Shutdown)
+
+	default:
+		return fmt.Errorf("unsupported transport: %s", s.config.Transport)
+	}
+}
+
+// serveHTTP runs httpServer until it fails or ctx is cancelled, in which case
+// shutdown is given shutdownTimeout to close sessions and drain requests
+func serveHTTP(ctx context.Context, httpServer *http.Server, shutdown func(context.Context) error) error {
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down HTTP transport: %w", err)
+		}
+
+		if err := <-errChan; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHTTPTransportStopsOnCancel(t *testing.T) {
+	for _, transport := range []string{TransportSSE, TransportStreamableHTTP} {
+		t.Run(transport, func(t *testing.T) {
+			srv, _ := newTestServer(t)
+			srv.config.Transport = transport
+			srv.config.ListenAddr = "127.0.0.1:0"
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() {
+				done <- srv.Start(ctx)
+			}()
+
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Expected clean shutdown, got: %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Server did not stop after context cancellation")
+			}
+		})
+	}
+}
+
+func TestUnsupportedTransport(t *testing.T) {
+	srv, _ := newTestServer(t)
+	srv.config.Transport = "carrier-pigeon"
+
+	if err := srv.Start(context.Background()); err == nil {
+		t.Error("Expected unsupported transport to fail")
+	}
+}
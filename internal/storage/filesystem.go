@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/markopolo123/prompt-mcp/internal/prompt"
 )
@@ -18,6 +19,8 @@ type FileSystemStorage struct {
 type Config struct {
 	PromptsDir   string
 	WatchChanges bool
+	Debounce     time.Duration   // quiet period before a reload; DefaultDebounce if zero
+	LoadMode     prompt.LoadMode // how invalid prompt files are handled; strict if empty
 }
 
 // NewFileSystemStorage creates a new filesystem storage instance
@@ -28,6 +31,9 @@ func NewFileSystemStorage(config Config) (*FileSystemStorage, error) {
 	}
 
 	loader := prompt.NewLoader(config.PromptsDir)
+	if config.LoadMode != "" {
+		loader.SetMode(config.LoadMode)
+	}
 
 	return &FileSystemStorage{
 		loader: loader,
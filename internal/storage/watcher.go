@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/markopolo123/prompt-mcp/internal/prompt"
+)
+
+// DefaultDebounce is the quiet period used when Config.Debounce is not set
+const DefaultDebounce = 500 * time.Millisecond
+
+// Watch watches the prompts directory recursively and calls onChange once
+// a burst of file system events has settled. It blocks until ctx is cancelled.
+func (fs *FileSystemStorage) Watch(ctx context.Context, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchesRecursive(watcher, fs.config.PromptsDir); err != nil {
+		return fmt.Errorf("failed to watch prompts directory: %w", err)
+	}
+
+	debounce := fs.config.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	// The timer is created stopped and armed on the first relevant event
+	timer := time.NewTimer(debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// Newly created directories must be watched explicitly
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addWatchesRecursive(watcher, event.Name); err != nil {
+						log.Printf("Warning: failed to watch new directory %s: %v", event.Name, err)
+					}
+				}
+			}
+
+			if !isRelevantEvent(event) {
+				continue
+			}
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Warning: file watcher error: %v", err)
+
+		case <-timer.C:
+			onChange()
+		}
+	}
+}
+
+// addWatchesRecursive adds a watch for dir and every directory below it
+func addWatchesRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		// Skip hidden directories such as .git
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(path)
+	})
+}
+
+// isRelevantEvent reports whether an event may change the prompt library
+func isRelevantEvent(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	name := filepath.Base(event.Name)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	// Removed or renamed directories have no extension and still matter
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == prompt.PartialExt || ext == "" || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
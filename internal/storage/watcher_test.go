@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDebouncesChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, "testing"), 0755); err != nil {
+		t.Fatalf("Failed to create category dir: %v", err)
+	}
+
+	fs, err := NewFileSystemStorage(Config{
+		PromptsDir:   tempDir,
+		WatchChanges: true,
+		Debounce:     100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- fs.Watch(ctx, func() { changes <- struct{}{} })
+	}()
+
+	// Give the watcher time to register its watches
+	time.Sleep(50 * time.Millisecond)
+
+	// A burst of writes should produce a single change notification
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(tempDir, "testing", "burst.yaml")
+		if err := os.WriteFile(file, []byte("metadata: {}\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a change notification")
+	}
+
+	select {
+	case <-changes:
+		t.Error("Expected the burst to be debounced into one notification")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch returned error: %v", err)
+	}
+}
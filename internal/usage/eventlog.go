@@ -0,0 +1,133 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// EventLogStore appends every event as a JSON line to a log file. Each event
+// is written as it is recorded, so a crash loses nothing and concurrent
+// processes can append to the same log. The aggregate is rebuilt by
+// replaying the log when the store is opened.
+type EventLogStore struct {
+	*MemoryStore
+
+	file *os.File
+}
+
+// OpenEventLogStore opens or creates the event log at path
+func OpenEventLogStore(path string) (*EventLogStore, error) {
+	store := &EventLogStore{MemoryStore: NewMemoryStore()}
+
+	if err := store.replay(path); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create usage log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage log: %w", err)
+	}
+	if err := terminateLastLine(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	store.file = file
+	return store, nil
+}
+
+// terminateLastLine ends a log whose last line was torn with a newline, so
+// that the next event starts on a line of its own
+func terminateLastLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("failed to read usage log: %w", err)
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	if _, err := file.Write([]byte{'\n'}); err != nil {
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+	return nil
+}
+
+// replay rebuilds the aggregate from an existing log
+func (e *EventLogStore) replay(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// A torn final write should not make the whole log unusable
+			log.Printf("Warning: skipping malformed usage event at %s:%d: %v", path, line, err)
+			continue
+		}
+		e.applyLocked(event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read usage log: %w", err)
+	}
+	return nil
+}
+
+// Record appends an event to the log
+func (e *EventLogStore) Record(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage event: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.applyLocked(event)
+
+	// A single write per event keeps lines whole when appended concurrently
+	if _, err := e.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage event: %w", err)
+	}
+	return nil
+}
+
+// Flush is a no-op: events are written to the log as they are recorded
+func (e *EventLogStore) Flush() error {
+	return nil
+}
+
+// Close closes the log file
+func (e *EventLogStore) Close() error {
+	return e.file.Close()
+}
@@ -0,0 +1,237 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultFlushInterval is used by the JSON store when no interval is set
+const DefaultFlushInterval = 30 * time.Second
+
+// lockTimeout bounds how long a flush waits for another process to release
+// the statistics file; older lock files are treated as stale
+const lockTimeout = 10 * time.Second
+
+// JSONStore keeps aggregated statistics in a single JSON file. Events are
+// aggregated in memory and merged into the file periodically and on Close,
+// so that several server processes can share one file.
+type JSONStore struct {
+	*MemoryStore
+
+	path string
+	// pending holds the events recorded since the last flush
+	pending map[string]*Stats
+	// writeMu serialises file writes; MemoryStore.mu guards pending
+	writeMu sync.Mutex
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// OpenJSONStore opens or creates the JSON statistics file at path
+func OpenJSONStore(path string, flushInterval time.Duration) (*JSONStore, error) {
+	store := &JSONStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+		pending:     make(map[string]*Stats),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	stats, err := readStatsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	store.stats = stats
+
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	go store.flushPeriodically(flushInterval)
+
+	return store, nil
+}
+
+// Record records a single use of a prompt
+func (j *JSONStore) Record(event Event) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.applyLocked(event)
+	addEvent(j.pending, event)
+	return nil
+}
+
+// Flush merges the events recorded since the last flush into the statistics
+// file. The file is re-read under a lock so that counts written by other
+// processes sharing it are kept, and the in-memory aggregate is refreshed
+// from the merged result.
+func (j *JSONStore) Flush() error {
+	j.writeMu.Lock()
+	defer j.writeMu.Unlock()
+
+	j.mu.Lock()
+	pending := j.pending
+	j.pending = make(map[string]*Stats)
+	j.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	merged, err := j.mergeIntoFile(pending)
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		// Keep the events for the next flush
+		mergeStats(j.pending, pending)
+		return err
+	}
+
+	// Events recorded during the write are not in the file yet
+	mergeStats(merged, j.pending)
+	j.stats = merged
+	return nil
+}
+
+// mergeIntoFile adds pending to the statistics file under the lock and
+// returns the merged statistics
+func (j *JSONStore) mergeIntoFile(pending map[string]*Stats) (map[string]*Stats, error) {
+	unlock, err := lockFile(j.path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	stats, err := readStatsFile(j.path)
+	if err != nil {
+		return nil, err
+	}
+	mergeStats(stats, pending)
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode usage stats: %w", err)
+	}
+	if err := writeFileAtomic(j.path, data); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Close stops the periodic flush and writes any pending statistics
+func (j *JSONStore) Close() error {
+	j.closeOnce.Do(func() {
+		close(j.stop)
+		<-j.done
+	})
+	return j.Flush()
+}
+
+// flushPeriodically flushes the store every interval until Close is called
+func (j *JSONStore) flushPeriodically(interval time.Duration) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			if err := j.Flush(); err != nil {
+				log.Printf("Warning: failed to flush usage stats: %v", err)
+			}
+		}
+	}
+}
+
+// readStatsFile reads aggregated statistics from path; a missing file holds
+// no statistics
+func readStatsFile(path string) (map[string]*Stats, error) {
+	stats := make(map[string]*Stats)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage stats: %w", err)
+	}
+
+	var decoded map[string]*Stats
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to parse usage stats %s: %w", path, err)
+	}
+	for id, s := range decoded {
+		if s != nil {
+			stats[id] = s
+		}
+	}
+	return stats, nil
+}
+
+// lockFile takes an exclusive lock by creating path, waiting while another
+// process holds it. The returned function releases the lock.
+func lockFile(path string) (unlock func(), err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create usage stats directory: %w", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock usage stats: %w", err)
+		}
+
+		// A lock left behind by a crashed process is removed once stale
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockTimeout {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for usage stats lock %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create usage stats directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write usage stats: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write usage stats: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace usage stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package usage
+
+import "sync"
+
+// MemoryStore keeps usage statistics in memory only. It is used when
+// persistence is disabled and as the aggregate behind the file backends.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	stats map[string]*Stats
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		stats: make(map[string]*Stats),
+	}
+}
+
+// Record records a single use of a prompt
+func (m *MemoryStore) Record(event Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.applyLocked(event)
+	return nil
+}
+
+// applyLocked adds event to the aggregate; m.mu must be held
+func (m *MemoryStore) applyLocked(event Event) {
+	addEvent(m.stats, event)
+}
+
+// Get returns the aggregated statistics for a prompt
+func (m *MemoryStore) Get(promptID string) (Stats, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats, exists := m.stats[promptID]
+	if !exists {
+		return Stats{}, false
+	}
+	return stats.clone(), true
+}
+
+// All returns the aggregated statistics for every prompt
+func (m *MemoryStore) All() map[string]Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := make(map[string]Stats, len(m.stats))
+	for id, stats := range m.stats {
+		all[id] = stats.clone()
+	}
+	return all
+}
+
+// Flush is a no-op for the in-memory store
+func (m *MemoryStore) Flush() error {
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,200 @@
+// Package usage records prompt usage statistics outside of the prompt files,
+// so that tracking usage never dirties the Git-tracked prompt library.
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Supported store backends
+const (
+	BackendNone     = "none"
+	BackendJSON     = "json"
+	BackendEventLog = "eventlog"
+)
+
+// Event is a single use of a prompt
+type Event struct {
+	PromptID  string    `json:"prompt_id"`
+	Time      time.Time `json:"time"`
+	Arguments []string  `json:"arguments,omitempty"` // names of the arguments supplied
+	Caller    string    `json:"caller,omitempty"`    // client name, if known
+}
+
+// Stats aggregates the usage of a single prompt
+type Stats struct {
+	UsageCount int            `json:"usage_count"`
+	LastUsed   time.Time      `json:"last_used"`
+	Arguments  map[string]int `json:"arguments,omitempty"` // argument name -> times supplied
+	Callers    map[string]int `json:"callers,omitempty"`   // caller -> times used
+}
+
+// Store persists usage events
+type Store interface {
+	// Record records a single use of a prompt
+	Record(event Event) error
+	// Get returns the aggregated statistics for a prompt
+	Get(promptID string) (Stats, bool)
+	// All returns the aggregated statistics for every prompt, keyed by ID
+	All() map[string]Stats
+	// Flush writes any buffered statistics to disk
+	Flush() error
+	// Close flushes and releases the store
+	Close() error
+}
+
+// Config selects and configures a store backend
+type Config struct {
+	Backend       string        // one of the Backend* constants
+	Path          string        // file used by the json and eventlog backends
+	FlushInterval time.Duration // how often the json backend writes to disk
+}
+
+// DefaultPath returns the default location of the usage file for backend,
+// inside the user's cache directory rather than the prompts repository
+func DefaultPath(backend string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	name := "usage-stats.json"
+	if backend == BackendEventLog {
+		name = "usage-events.jsonl"
+	}
+	return filepath.Join(dir, "prompt-mcp", name)
+}
+
+// IsValidBackend reports whether backend names a supported store backend
+func IsValidBackend(backend string) bool {
+	switch backend {
+	case BackendNone, BackendJSON, BackendEventLog:
+		return true
+	default:
+		return false
+	}
+}
+
+// Open creates the store selected by config
+func Open(config Config) (Store, error) {
+	path := config.Path
+	if path == "" {
+		path = DefaultPath(config.Backend)
+	}
+
+	switch config.Backend {
+	case "", BackendNone:
+		return NewMemoryStore(), nil
+	case BackendJSON:
+		return OpenJSONStore(path, config.FlushInterval)
+	case BackendEventLog:
+		return OpenEventLogStore(path)
+	default:
+		return nil, fmt.Errorf("unsupported usage stats backend: %s", config.Backend)
+	}
+}
+
+// apply adds an event to the aggregated statistics
+func (s *Stats) apply(event Event) {
+	s.UsageCount++
+	if event.Time.After(s.LastUsed) {
+		s.LastUsed = event.Time
+	}
+
+	for _, name := range event.Arguments {
+		if s.Arguments == nil {
+			s.Arguments = make(map[string]int)
+		}
+		s.Arguments[name]++
+	}
+
+	if event.Caller != "" {
+		if s.Callers == nil {
+			s.Callers = make(map[string]int)
+		}
+		s.Callers[event.Caller]++
+	}
+}
+
+// merge adds the counts of other to the statistics
+func (s *Stats) merge(other Stats) {
+	s.UsageCount += other.UsageCount
+	if other.LastUsed.After(s.LastUsed) {
+		s.LastUsed = other.LastUsed
+	}
+	s.Arguments = addCounts(s.Arguments, other.Arguments)
+	s.Callers = addCounts(s.Callers, other.Callers)
+}
+
+// addCounts adds the counts in other to counts, allocating it if needed
+func addCounts(counts, other map[string]int) map[string]int {
+	for key, value := range other {
+		if counts == nil {
+			counts = make(map[string]int)
+		}
+		counts[key] += value
+	}
+	return counts
+}
+
+// addEvent adds an event to the statistics of its prompt in stats
+func addEvent(stats map[string]*Stats, event Event) {
+	s, exists := stats[event.PromptID]
+	if !exists {
+		s = &Stats{}
+		stats[event.PromptID] = s
+	}
+	s.apply(event)
+}
+
+// mergeStats adds every entry of other to stats
+func mergeStats(stats, other map[string]*Stats) {
+	for id, s := range other {
+		existing, exists := stats[id]
+		if !exists {
+			existing = &Stats{}
+			stats[id] = existing
+		}
+		existing.merge(*s)
+	}
+}
+
+// clone returns a deep copy so callers cannot mutate store state
+func (s Stats) clone() Stats {
+	copied := s
+	copied.Arguments = copyCounts(s.Arguments)
+	copied.Callers = copyCounts(s.Callers)
+	return copied
+}
+
+// copyCounts copies a count map, preserving nil
+func copyCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return nil
+	}
+	copied := make(map[string]int, len(counts))
+	for key, value := range counts {
+		copied[key] = value
+	}
+	return copied
+}
+
+// NewEvent builds an event for promptID at now, with argument names sorted
+func NewEvent(promptID string, arguments map[string]string, caller string) Event {
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return Event{
+		PromptID:  promptID,
+		Time:      time.Now().UTC(),
+		Arguments: names,
+		Caller:    caller,
+	}
+}
@@ -0,0 +1,208 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoresPersistAcrossReopen(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendEventLog} {
+		t.Run(backend, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "stats", "usage")
+			config := Config{Backend: backend, Path: path, FlushInterval: time.Hour}
+
+			store, err := Open(config)
+			if err != nil {
+				t.Fatalf("Failed to open store: %v", err)
+			}
+
+			first := NewEvent("code-review", map[string]string{"language": "go"}, "claude-code")
+			second := NewEvent("code-review", map[string]string{"language": "go", "focus": "security"}, "")
+			for _, event := range []Event{first, second} {
+				if err := store.Record(event); err != nil {
+					t.Fatalf("Failed to record event: %v", err)
+				}
+			}
+
+			if err := store.Close(); err != nil {
+				t.Fatalf("Failed to close store: %v", err)
+			}
+
+			reopened, err := Open(config)
+			if err != nil {
+				t.Fatalf("Failed to reopen store: %v", err)
+			}
+			defer reopened.Close()
+
+			stats, exists := reopened.Get("code-review")
+			if !exists {
+				t.Fatal("Expected stats to survive reopening")
+			}
+			if stats.UsageCount != 2 {
+				t.Errorf("Expected usage count 2, got %d", stats.UsageCount)
+			}
+			if !stats.LastUsed.Equal(second.Time) {
+				t.Errorf("Expected last used %v, got %v", second.Time, stats.LastUsed)
+			}
+			if stats.Arguments["language"] != 2 || stats.Arguments["focus"] != 1 {
+				t.Errorf("Unexpected argument counts: %v", stats.Arguments)
+			}
+			if stats.Callers["claude-code"] != 1 || len(stats.Callers) != 1 {
+				t.Errorf("Unexpected caller counts: %v", stats.Callers)
+			}
+		})
+	}
+}
+
+func TestEventLogSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	content := `{"prompt_id":"a","time":"2025-08-27T10:00:00Z"}
+{"prompt_id":"a","ti
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	store, err := OpenEventLogStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open event log: %v", err)
+	}
+	defer store.Close()
+
+	if stats, _ := store.Get("a"); stats.UsageCount != 1 {
+		t.Errorf("Expected one valid event, got %d", stats.UsageCount)
+	}
+}
+
+func TestEventLogRecoversFromTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	content := `{"prompt_id":"a","time":"2025-08-27T10:00:00Z"}
+{"prompt_id":"a","ti`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	store, err := OpenEventLogStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open event log: %v", err)
+	}
+	if err := store.Record(NewEvent("a", nil, "")); err != nil {
+		t.Fatalf("Failed to record event: %v", err)
+	}
+	store.Close()
+
+	reopened, err := OpenEventLogStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen event log: %v", err)
+	}
+	defer reopened.Close()
+
+	if stats, _ := reopened.Get("a"); stats.UsageCount != 2 {
+		t.Errorf("Expected the new event to survive the torn line, got %d uses", stats.UsageCount)
+	}
+}
+
+func TestConcurrentRecord(t *testing.T) {
+	store, err := OpenJSONStore(filepath.Join(t.TempDir(), "usage.json"), time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Record(NewEvent("p", nil, ""))
+			store.Get("p")
+		}()
+	}
+	wg.Wait()
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close store: %v", err)
+	}
+
+	if stats, _ := store.Get("p"); stats.UsageCount != 50 {
+		t.Errorf("Expected 50 uses, got %d", stats.UsageCount)
+	}
+}
+
+func TestGetReturnsCopy(t *testing.T) {
+	store := NewMemoryStore()
+	store.Record(NewEvent("p", map[string]string{"x": "1"}, ""))
+
+	stats, _ := store.Get("p")
+	stats.Arguments["x"] = 100
+
+	if again, _ := store.Get("p"); again.Arguments["x"] != 1 {
+		t.Error("Mutating returned stats must not affect the store")
+	}
+}
+
+func TestJSONStoresShareFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+
+	first, err := OpenJSONStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	second, err := OpenJSONStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+
+	first.Record(NewEvent("p", map[string]string{"x": "1"}, "a"))
+	second.Record(NewEvent("p", nil, "b"))
+	second.Record(NewEvent("q", nil, ""))
+
+	for _, store := range []*JSONStore{first, second, first} {
+		if err := store.Flush(); err != nil {
+			t.Fatalf("Failed to flush store: %v", err)
+		}
+	}
+	first.Close()
+	second.Close()
+
+	reopened, err := OpenJSONStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	stats, _ := reopened.Get("p")
+	if stats.UsageCount != 2 || stats.Arguments["x"] != 1 || stats.Callers["a"] != 1 || stats.Callers["b"] != 1 {
+		t.Errorf("Expected counts from both stores, got %+v", stats)
+	}
+	if stats, _ := reopened.Get("q"); stats.UsageCount != 1 {
+		t.Errorf("Expected usage count 1, got %d", stats.UsageCount)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Expected lock file to be removed, got %v", err)
+	}
+}
+
+func TestEventLogWritesOnRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	store, err := OpenEventLogStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open event log: %v", err)
+	}
+	defer store.Close()
+
+	store.Record(NewEvent("a", nil, ""))
+
+	// The event must reach the file without a flush or close
+	reader, err := OpenEventLogStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open event log: %v", err)
+	}
+	defer reader.Close()
+
+	if stats, _ := reader.Get("a"); stats.UsageCount != 1 {
+		t.Errorf("Expected the recorded event in the log, got %d uses", stats.UsageCount)
+	}
+}